@@ -9,6 +9,8 @@ package gosv
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -22,8 +24,13 @@ const (
 )
 
 type ProcessRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// group addresses all members of a group; name may also be "group:*"
+	Group string `protobuf:"bytes,2,opt,name=group,proto3" json:"group,omitempty"`
+	// force makes StartProcess restart processes that are already running
+	// instead of leaving them alone
+	Force         bool `protobuf:"varint,3,opt,name=force,proto3" json:"force,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -65,6 +72,20 @@ func (x *ProcessRequest) GetName() string {
 	return ""
 }
 
+func (x *ProcessRequest) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+func (x *ProcessRequest) GetForce() bool {
+	if x != nil {
+		return x.Force
+	}
+	return false
+}
+
 type StatusRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
@@ -101,10 +122,17 @@ func (*StatusRequest) Descriptor() ([]byte, []int) {
 	return file_api_supervisor_proto_rawDescGZIP(), []int{1}
 }
 
+// Response is returned by control RPCs. Failures are reported as gRPC
+// status errors: NotFound for unknown processes and groups,
+// FailedPrecondition for processes that are already running or not
+// running, InvalidArgument for bad requests and Aborted for processes that
+// failed to start. success is therefore always true.
 type Response struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Success bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	// processes holds the resulting status of the addressed processes
+	Processes     []*ProcessStatus `protobuf:"bytes,3,rep,name=processes,proto3" json:"processes,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -153,13 +181,30 @@ func (x *Response) GetMessage() string {
 	return ""
 }
 
+func (x *Response) GetProcesses() []*ProcessStatus {
+	if x != nil {
+		return x.Processes
+	}
+	return nil
+}
+
 type ProcessStatus struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
-	Pid           int32                  `protobuf:"varint,3,opt,name=pid,proto3" json:"pid,omitempty"`
-	Restarts      int32                  `protobuf:"varint,4,opt,name=restarts,proto3" json:"restarts,omitempty"`
-	Error         string                 `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	Name         string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Status       string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	Pid          int32                  `protobuf:"varint,3,opt,name=pid,proto3" json:"pid,omitempty"`
+	Restarts     int32                  `protobuf:"varint,4,opt,name=restarts,proto3" json:"restarts,omitempty"`
+	Error        string                 `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	StartRetries int32                  `protobuf:"varint,6,opt,name=start_retries,json=startRetries,proto3" json:"start_retries,omitempty"`
+	Ready        bool                   `protobuf:"varint,7,opt,name=ready,proto3" json:"ready,omitempty"`
+	Group        string                 `protobuf:"bytes,8,opt,name=group,proto3" json:"group,omitempty"`
+	StartTime    *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	ExitCode     int32                  `protobuf:"varint,10,opt,name=exit_code,json=exitCode,proto3" json:"exit_code,omitempty"`
+	// max_restarts is negative for an unlimited budget
+	MaxRestarts   int32                `protobuf:"varint,11,opt,name=max_restarts,json=maxRestarts,proto3" json:"max_restarts,omitempty"`
+	RestartWindow *durationpb.Duration `protobuf:"bytes,12,opt,name=restart_window,json=restartWindow,proto3" json:"restart_window,omitempty"`
+	// usage is unset until the process has been sampled
+	Usage         *ResourceUsage `protobuf:"bytes,13,opt,name=usage,proto3" json:"usage,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -229,6 +274,172 @@ func (x *ProcessStatus) GetError() string {
 	return ""
 }
 
+func (x *ProcessStatus) GetStartRetries() int32 {
+	if x != nil {
+		return x.StartRetries
+	}
+	return 0
+}
+
+func (x *ProcessStatus) GetReady() bool {
+	if x != nil {
+		return x.Ready
+	}
+	return false
+}
+
+func (x *ProcessStatus) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+func (x *ProcessStatus) GetStartTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartTime
+	}
+	return nil
+}
+
+func (x *ProcessStatus) GetExitCode() int32 {
+	if x != nil {
+		return x.ExitCode
+	}
+	return 0
+}
+
+func (x *ProcessStatus) GetMaxRestarts() int32 {
+	if x != nil {
+		return x.MaxRestarts
+	}
+	return 0
+}
+
+func (x *ProcessStatus) GetRestartWindow() *durationpb.Duration {
+	if x != nil {
+		return x.RestartWindow
+	}
+	return nil
+}
+
+func (x *ProcessStatus) GetUsage() *ResourceUsage {
+	if x != nil {
+		return x.Usage
+	}
+	return nil
+}
+
+// ResourceUsage covers a process and all its descendants.
+type ResourceUsage struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// cpu_percent is measured over the last sampling interval; 100 is one core
+	CpuPercent    float64                `protobuf:"fixed64,1,opt,name=cpu_percent,json=cpuPercent,proto3" json:"cpu_percent,omitempty"`
+	CpuTime       *durationpb.Duration   `protobuf:"bytes,2,opt,name=cpu_time,json=cpuTime,proto3" json:"cpu_time,omitempty"`
+	RssBytes      uint64                 `protobuf:"varint,3,opt,name=rss_bytes,json=rssBytes,proto3" json:"rss_bytes,omitempty"`
+	Threads       int32                  `protobuf:"varint,4,opt,name=threads,proto3" json:"threads,omitempty"`
+	Fds           int32                  `protobuf:"varint,5,opt,name=fds,proto3" json:"fds,omitempty"`
+	ReadBytes     uint64                 `protobuf:"varint,6,opt,name=read_bytes,json=readBytes,proto3" json:"read_bytes,omitempty"`
+	WriteBytes    uint64                 `protobuf:"varint,7,opt,name=write_bytes,json=writeBytes,proto3" json:"write_bytes,omitempty"`
+	Processes     int32                  `protobuf:"varint,8,opt,name=processes,proto3" json:"processes,omitempty"`
+	Time          *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=time,proto3" json:"time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResourceUsage) Reset() {
+	*x = ResourceUsage{}
+	mi := &file_api_supervisor_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResourceUsage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResourceUsage) ProtoMessage() {}
+
+func (x *ResourceUsage) ProtoReflect() protoreflect.Message {
+	mi := &file_api_supervisor_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResourceUsage.ProtoReflect.Descriptor instead.
+func (*ResourceUsage) Descriptor() ([]byte, []int) {
+	return file_api_supervisor_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ResourceUsage) GetCpuPercent() float64 {
+	if x != nil {
+		return x.CpuPercent
+	}
+	return 0
+}
+
+func (x *ResourceUsage) GetCpuTime() *durationpb.Duration {
+	if x != nil {
+		return x.CpuTime
+	}
+	return nil
+}
+
+func (x *ResourceUsage) GetRssBytes() uint64 {
+	if x != nil {
+		return x.RssBytes
+	}
+	return 0
+}
+
+func (x *ResourceUsage) GetThreads() int32 {
+	if x != nil {
+		return x.Threads
+	}
+	return 0
+}
+
+func (x *ResourceUsage) GetFds() int32 {
+	if x != nil {
+		return x.Fds
+	}
+	return 0
+}
+
+func (x *ResourceUsage) GetReadBytes() uint64 {
+	if x != nil {
+		return x.ReadBytes
+	}
+	return 0
+}
+
+func (x *ResourceUsage) GetWriteBytes() uint64 {
+	if x != nil {
+		return x.WriteBytes
+	}
+	return 0
+}
+
+func (x *ResourceUsage) GetProcesses() int32 {
+	if x != nil {
+		return x.Processes
+	}
+	return 0
+}
+
+func (x *ResourceUsage) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
 type StatusResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Processes     []*ProcessStatus       `protobuf:"bytes,1,rep,name=processes,proto3" json:"processes,omitempty"`
@@ -238,7 +449,7 @@ type StatusResponse struct {
 
 func (x *StatusResponse) Reset() {
 	*x = StatusResponse{}
-	mi := &file_api_supervisor_proto_msgTypes[4]
+	mi := &file_api_supervisor_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -250,7 +461,7 @@ func (x *StatusResponse) String() string {
 func (*StatusResponse) ProtoMessage() {}
 
 func (x *StatusResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_supervisor_proto_msgTypes[4]
+	mi := &file_api_supervisor_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -263,7 +474,7 @@ func (x *StatusResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StatusResponse.ProtoReflect.Descriptor instead.
 func (*StatusResponse) Descriptor() ([]byte, []int) {
-	return file_api_supervisor_proto_rawDescGZIP(), []int{4}
+	return file_api_supervisor_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *StatusResponse) GetProcesses() []*ProcessStatus {
@@ -273,31 +484,1248 @@ func (x *StatusResponse) GetProcesses() []*ProcessStatus {
 	return nil
 }
 
-var File_api_supervisor_proto protoreflect.FileDescriptor
+// LogsRequest selects buffered log records; empty fields match everything.
+type LogsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// processes matches process names; a program name matches its instances
+	Processes []string `protobuf:"bytes,1,rep,name=processes,proto3" json:"processes,omitempty"`
+	// level is the minimum level: debug, info, warn or error
+	Level string `protobuf:"bytes,2,opt,name=level,proto3" json:"level,omitempty"`
+	// streams is any of supervisor, stdout and stderr
+	Streams []string               `protobuf:"bytes,3,rep,name=streams,proto3" json:"streams,omitempty"`
+	Since   *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=since,proto3" json:"since,omitempty"`
+	// limit returns only the most recent records
+	Limit         int32 `protobuf:"varint,5,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
 
-const file_api_supervisor_proto_rawDesc = "" +
-	"\n" +
-	"\x14api/supervisor.proto\x12\x04gosv\"$\n" +
-	"\x0eProcessRequest\x12\x12\n" +
-	"\x04name\x18\x01 \x01(\tR\x04name\"\x0f\n" +
-	"\rStatusRequest\">\n" +
-	"\bResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage\"\x7f\n" +
-	"\rProcessStatus\x12\x12\n" +
-	"\x04name\x18\x01 \x01(\tR\x04name\x12\x16\n" +
-	"\x06status\x18\x02 \x01(\tR\x06status\x12\x10\n" +
-	"\x03pid\x18\x03 \x01(\x05R\x03pid\x12\x1a\n" +
-	"\brestarts\x18\x04 \x01(\x05R\brestarts\x12\x14\n" +
-	"\x05error\x18\x05 \x01(\tR\x05error\"C\n" +
-	"\x0eStatusResponse\x121\n" +
-	"\tprocesses\x18\x01 \x03(\v2\x13.gosv.ProcessStatusR\tprocesses2\xef\x01\n" +
-	"\n" +
-	"Supervisor\x126\n" +
-	"\fStartProcess\x12\x14.gosv.ProcessRequest\x1a\x0e.gosv.Response\"\x00\x125\n" +
-	"\vStopProcess\x12\x14.gosv.ProcessRequest\x1a\x0e.gosv.Response\"\x00\x128\n" +
-	"\x0eRestartProcess\x12\x14.gosv.ProcessRequest\x1a\x0e.gosv.Response\"\x00\x128\n" +
-	"\tGetStatus\x12\x13.gosv.StatusRequest\x1a\x14.gosv.StatusResponse\"\x00B!Z\x1fgithub.com/kolkov/gosv/api/gosvb\x06proto3"
+func (x *LogsRequest) Reset() {
+	*x = LogsRequest{}
+	mi := &file_api_supervisor_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LogsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogsRequest) ProtoMessage() {}
+
+func (x *LogsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_supervisor_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogsRequest.ProtoReflect.Descriptor instead.
+func (*LogsRequest) Descriptor() ([]byte, []int) {
+	return file_api_supervisor_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *LogsRequest) GetProcesses() []string {
+	if x != nil {
+		return x.Processes
+	}
+	return nil
+}
+
+func (x *LogsRequest) GetLevel() string {
+	if x != nil {
+		return x.Level
+	}
+	return ""
+}
+
+func (x *LogsRequest) GetStreams() []string {
+	if x != nil {
+		return x.Streams
+	}
+	return nil
+}
+
+func (x *LogsRequest) GetSince() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Since
+	}
+	return nil
+}
+
+func (x *LogsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type LogRecord struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Time          *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=time,proto3" json:"time,omitempty"`
+	Level         string                 `protobuf:"bytes,2,opt,name=level,proto3" json:"level,omitempty"`
+	Process       string                 `protobuf:"bytes,3,opt,name=process,proto3" json:"process,omitempty"`
+	Pid           int32                  `protobuf:"varint,4,opt,name=pid,proto3" json:"pid,omitempty"`
+	Stream        string                 `protobuf:"bytes,5,opt,name=stream,proto3" json:"stream,omitempty"`
+	Message       string                 `protobuf:"bytes,6,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LogRecord) Reset() {
+	*x = LogRecord{}
+	mi := &file_api_supervisor_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LogRecord) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogRecord) ProtoMessage() {}
+
+func (x *LogRecord) ProtoReflect() protoreflect.Message {
+	mi := &file_api_supervisor_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogRecord.ProtoReflect.Descriptor instead.
+func (*LogRecord) Descriptor() ([]byte, []int) {
+	return file_api_supervisor_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *LogRecord) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *LogRecord) GetLevel() string {
+	if x != nil {
+		return x.Level
+	}
+	return ""
+}
+
+func (x *LogRecord) GetProcess() string {
+	if x != nil {
+		return x.Process
+	}
+	return ""
+}
+
+func (x *LogRecord) GetPid() int32 {
+	if x != nil {
+		return x.Pid
+	}
+	return 0
+}
+
+func (x *LogRecord) GetStream() string {
+	if x != nil {
+		return x.Stream
+	}
+	return ""
+}
+
+func (x *LogRecord) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type LogsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Records       []*LogRecord           `protobuf:"bytes,1,rep,name=records,proto3" json:"records,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LogsResponse) Reset() {
+	*x = LogsResponse{}
+	mi := &file_api_supervisor_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LogsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogsResponse) ProtoMessage() {}
+
+func (x *LogsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_supervisor_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogsResponse.ProtoReflect.Descriptor instead.
+func (*LogsResponse) Descriptor() ([]byte, []int) {
+	return file_api_supervisor_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *LogsResponse) GetRecords() []*LogRecord {
+	if x != nil {
+		return x.Records
+	}
+	return nil
+}
+
+// StreamLogsRequest selects the records StreamLogs sends: first the most
+// recent buffered ones, then, with follow, new records as they arrive.
+type StreamLogsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// name is a process, instance, program or "group:*"; empty means all
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// group selects all members of a group instead of name
+	Group string `protobuf:"bytes,2,opt,name=group,proto3" json:"group,omitempty"`
+	// streams is any of supervisor, stdout and stderr; empty means all
+	Streams []string `protobuf:"bytes,3,rep,name=streams,proto3" json:"streams,omitempty"`
+	// tail is how many buffered records to send; negative sends all of them
+	Tail          int32                  `protobuf:"varint,4,opt,name=tail,proto3" json:"tail,omitempty"`
+	Follow        bool                   `protobuf:"varint,5,opt,name=follow,proto3" json:"follow,omitempty"`
+	Since         *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=since,proto3" json:"since,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamLogsRequest) Reset() {
+	*x = StreamLogsRequest{}
+	mi := &file_api_supervisor_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamLogsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamLogsRequest) ProtoMessage() {}
+
+func (x *StreamLogsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_supervisor_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamLogsRequest.ProtoReflect.Descriptor instead.
+func (*StreamLogsRequest) Descriptor() ([]byte, []int) {
+	return file_api_supervisor_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *StreamLogsRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *StreamLogsRequest) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+func (x *StreamLogsRequest) GetStreams() []string {
+	if x != nil {
+		return x.Streams
+	}
+	return nil
+}
+
+func (x *StreamLogsRequest) GetTail() int32 {
+	if x != nil {
+		return x.Tail
+	}
+	return 0
+}
+
+func (x *StreamLogsRequest) GetFollow() bool {
+	if x != nil {
+		return x.Follow
+	}
+	return false
+}
+
+func (x *StreamLogsRequest) GetSince() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Since
+	}
+	return nil
+}
+
+// WatchRequest selects the processes whose status transitions WatchEvents
+// sends.
+type WatchRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// name is a process, instance, program or "group:*"; empty means all
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// group selects all members of a group instead of name
+	Group string `protobuf:"bytes,2,opt,name=group,proto3" json:"group,omitempty"`
+	// states only sends transitions into these states; empty means all
+	States []string `protobuf:"bytes,3,rep,name=states,proto3" json:"states,omitempty"`
+	// snapshot first sends the current state of every selected process
+	Snapshot      bool `protobuf:"varint,4,opt,name=snapshot,proto3" json:"snapshot,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchRequest) Reset() {
+	*x = WatchRequest{}
+	mi := &file_api_supervisor_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchRequest) ProtoMessage() {}
+
+func (x *WatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_supervisor_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchRequest.ProtoReflect.Descriptor instead.
+func (*WatchRequest) Descriptor() ([]byte, []int) {
+	return file_api_supervisor_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *WatchRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *WatchRequest) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+func (x *WatchRequest) GetStates() []string {
+	if x != nil {
+		return x.States
+	}
+	return nil
+}
+
+func (x *WatchRequest) GetSnapshot() bool {
+	if x != nil {
+		return x.Snapshot
+	}
+	return false
+}
+
+type ProcessEvent struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Time     *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=time,proto3" json:"time,omitempty"`
+	Process  string                 `protobuf:"bytes,2,opt,name=process,proto3" json:"process,omitempty"`
+	Group    string                 `protobuf:"bytes,3,opt,name=group,proto3" json:"group,omitempty"`
+	From     string                 `protobuf:"bytes,4,opt,name=from,proto3" json:"from,omitempty"`
+	To       string                 `protobuf:"bytes,5,opt,name=to,proto3" json:"to,omitempty"`
+	Pid      int32                  `protobuf:"varint,6,opt,name=pid,proto3" json:"pid,omitempty"`
+	ExitCode int32                  `protobuf:"varint,7,opt,name=exit_code,json=exitCode,proto3" json:"exit_code,omitempty"`
+	Error    string                 `protobuf:"bytes,8,opt,name=error,proto3" json:"error,omitempty"`
+	// snapshot marks the current-state events sent on request; from is empty
+	Snapshot bool `protobuf:"varint,9,opt,name=snapshot,proto3" json:"snapshot,omitempty"`
+	// snapshot_end is set on the last snapshot event
+	SnapshotEnd   bool `protobuf:"varint,10,opt,name=snapshot_end,json=snapshotEnd,proto3" json:"snapshot_end,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProcessEvent) Reset() {
+	*x = ProcessEvent{}
+	mi := &file_api_supervisor_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProcessEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProcessEvent) ProtoMessage() {}
+
+func (x *ProcessEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_api_supervisor_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProcessEvent.ProtoReflect.Descriptor instead.
+func (*ProcessEvent) Descriptor() ([]byte, []int) {
+	return file_api_supervisor_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ProcessEvent) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *ProcessEvent) GetProcess() string {
+	if x != nil {
+		return x.Process
+	}
+	return ""
+}
+
+func (x *ProcessEvent) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+func (x *ProcessEvent) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *ProcessEvent) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *ProcessEvent) GetPid() int32 {
+	if x != nil {
+		return x.Pid
+	}
+	return 0
+}
+
+func (x *ProcessEvent) GetExitCode() int32 {
+	if x != nil {
+		return x.ExitCode
+	}
+	return 0
+}
+
+func (x *ProcessEvent) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *ProcessEvent) GetSnapshot() bool {
+	if x != nil {
+		return x.Snapshot
+	}
+	return false
+}
+
+func (x *ProcessEvent) GetSnapshotEnd() bool {
+	if x != nil {
+		return x.SnapshotEnd
+	}
+	return false
+}
+
+type ReloadRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// dry_run computes the change set without applying it
+	DryRun        bool `protobuf:"varint,1,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReloadRequest) Reset() {
+	*x = ReloadRequest{}
+	mi := &file_api_supervisor_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReloadRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReloadRequest) ProtoMessage() {}
+
+func (x *ReloadRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_supervisor_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReloadRequest.ProtoReflect.Descriptor instead.
+func (*ReloadRequest) Descriptor() ([]byte, []int) {
+	return file_api_supervisor_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ReloadRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+// ProcessConfigRequest carries one entry of the processes section, in the
+// YAML syntax of the config file.
+type ProcessConfigRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Config        string                 `protobuf:"bytes,1,opt,name=config,proto3" json:"config,omitempty"`
+	DryRun        bool                   `protobuf:"varint,2,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProcessConfigRequest) Reset() {
+	*x = ProcessConfigRequest{}
+	mi := &file_api_supervisor_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProcessConfigRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProcessConfigRequest) ProtoMessage() {}
+
+func (x *ProcessConfigRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_supervisor_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProcessConfigRequest.ProtoReflect.Descriptor instead.
+func (*ProcessConfigRequest) Descriptor() ([]byte, []int) {
+	return file_api_supervisor_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ProcessConfigRequest) GetConfig() string {
+	if x != nil {
+		return x.Config
+	}
+	return ""
+}
+
+func (x *ProcessConfigRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+type RemoveProcessRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	DryRun        bool                   `protobuf:"varint,2,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveProcessRequest) Reset() {
+	*x = RemoveProcessRequest{}
+	mi := &file_api_supervisor_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveProcessRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveProcessRequest) ProtoMessage() {}
+
+func (x *RemoveProcessRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_supervisor_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveProcessRequest.ProtoReflect.Descriptor instead.
+func (*RemoveProcessRequest) Descriptor() ([]byte, []int) {
+	return file_api_supervisor_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *RemoveProcessRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *RemoveProcessRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+// ChangeSet lists programs by how a configuration change affects them.
+type ChangeSet struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Added         []string               `protobuf:"bytes,1,rep,name=added,proto3" json:"added,omitempty"`
+	Updated       []string               `protobuf:"bytes,2,rep,name=updated,proto3" json:"updated,omitempty"`
+	Removed       []string               `protobuf:"bytes,3,rep,name=removed,proto3" json:"removed,omitempty"`
+	Unchanged     []string               `protobuf:"bytes,4,rep,name=unchanged,proto3" json:"unchanged,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChangeSet) Reset() {
+	*x = ChangeSet{}
+	mi := &file_api_supervisor_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChangeSet) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChangeSet) ProtoMessage() {}
+
+func (x *ChangeSet) ProtoReflect() protoreflect.Message {
+	mi := &file_api_supervisor_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChangeSet.ProtoReflect.Descriptor instead.
+func (*ChangeSet) Descriptor() ([]byte, []int) {
+	return file_api_supervisor_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ChangeSet) GetAdded() []string {
+	if x != nil {
+		return x.Added
+	}
+	return nil
+}
+
+func (x *ChangeSet) GetUpdated() []string {
+	if x != nil {
+		return x.Updated
+	}
+	return nil
+}
+
+func (x *ChangeSet) GetRemoved() []string {
+	if x != nil {
+		return x.Removed
+	}
+	return nil
+}
+
+func (x *ChangeSet) GetUnchanged() []string {
+	if x != nil {
+		return x.Unchanged
+	}
+	return nil
+}
+
+type ChangeResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Message string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	Changes *ChangeSet             `protobuf:"bytes,2,opt,name=changes,proto3" json:"changes,omitempty"`
+	// dry_run is set when the changes were not applied
+	DryRun bool `protobuf:"varint,3,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	// processes holds the resulting status of the changed processes
+	Processes     []*ProcessStatus `protobuf:"bytes,4,rep,name=processes,proto3" json:"processes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChangeResponse) Reset() {
+	*x = ChangeResponse{}
+	mi := &file_api_supervisor_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChangeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChangeResponse) ProtoMessage() {}
+
+func (x *ChangeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_supervisor_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChangeResponse.ProtoReflect.Descriptor instead.
+func (*ChangeResponse) Descriptor() ([]byte, []int) {
+	return file_api_supervisor_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ChangeResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ChangeResponse) GetChanges() *ChangeSet {
+	if x != nil {
+		return x.Changes
+	}
+	return nil
+}
+
+func (x *ChangeResponse) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+func (x *ChangeResponse) GetProcesses() []*ProcessStatus {
+	if x != nil {
+		return x.Processes
+	}
+	return nil
+}
+
+// AuditRequest selects audit records; empty fields match everything.
+type AuditRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// name matches the target; a program name matches its instances
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// actor matches part of the actor, e.g. a token name or a user
+	Actor string `protobuf:"bytes,2,opt,name=actor,proto3" json:"actor,omitempty"`
+	// action is start, stop, restart, signal, reload, add, update or remove
+	Action string                 `protobuf:"bytes,3,opt,name=action,proto3" json:"action,omitempty"`
+	Since  *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=since,proto3" json:"since,omitempty"`
+	Until  *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=until,proto3" json:"until,omitempty"`
+	// failed_only returns only failed and denied actions
+	FailedOnly bool `protobuf:"varint,6,opt,name=failed_only,json=failedOnly,proto3" json:"failed_only,omitempty"`
+	// limit returns only the most recent records
+	Limit         int32 `protobuf:"varint,7,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AuditRequest) Reset() {
+	*x = AuditRequest{}
+	mi := &file_api_supervisor_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AuditRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuditRequest) ProtoMessage() {}
+
+func (x *AuditRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_supervisor_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuditRequest.ProtoReflect.Descriptor instead.
+func (*AuditRequest) Descriptor() ([]byte, []int) {
+	return file_api_supervisor_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *AuditRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *AuditRequest) GetActor() string {
+	if x != nil {
+		return x.Actor
+	}
+	return ""
+}
+
+func (x *AuditRequest) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *AuditRequest) GetSince() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Since
+	}
+	return nil
+}
+
+func (x *AuditRequest) GetUntil() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Until
+	}
+	return nil
+}
+
+func (x *AuditRequest) GetFailedOnly() bool {
+	if x != nil {
+		return x.FailedOnly
+	}
+	return false
+}
+
+func (x *AuditRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type AuditRecord struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Time  *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=time,proto3" json:"time,omitempty"`
+	// actor is "token:<name>", "cert:<common name>", "uid:<uid>(<user>)"
+	// with the pid of the client, or the address of the client
+	Actor string `protobuf:"bytes,2,opt,name=actor,proto3" json:"actor,omitempty"`
+	// source is grpc, socket, tui, cli or signal
+	Source string `protobuf:"bytes,3,opt,name=source,proto3" json:"source,omitempty"`
+	Action string `protobuf:"bytes,4,opt,name=action,proto3" json:"action,omitempty"`
+	Target string `protobuf:"bytes,5,opt,name=target,proto3" json:"target,omitempty"`
+	// detail holds arguments such as the signal name
+	Detail string `protobuf:"bytes,6,opt,name=detail,proto3" json:"detail,omitempty"`
+	// result is ok, failed or denied
+	Result string `protobuf:"bytes,7,opt,name=result,proto3" json:"result,omitempty"`
+	Error  string `protobuf:"bytes,8,opt,name=error,proto3" json:"error,omitempty"`
+	// processes lists the processes a group or program target stood for
+	Processes     []string `protobuf:"bytes,9,rep,name=processes,proto3" json:"processes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AuditRecord) Reset() {
+	*x = AuditRecord{}
+	mi := &file_api_supervisor_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AuditRecord) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuditRecord) ProtoMessage() {}
+
+func (x *AuditRecord) ProtoReflect() protoreflect.Message {
+	mi := &file_api_supervisor_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuditRecord.ProtoReflect.Descriptor instead.
+func (*AuditRecord) Descriptor() ([]byte, []int) {
+	return file_api_supervisor_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *AuditRecord) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *AuditRecord) GetActor() string {
+	if x != nil {
+		return x.Actor
+	}
+	return ""
+}
+
+func (x *AuditRecord) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *AuditRecord) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *AuditRecord) GetTarget() string {
+	if x != nil {
+		return x.Target
+	}
+	return ""
+}
+
+func (x *AuditRecord) GetDetail() string {
+	if x != nil {
+		return x.Detail
+	}
+	return ""
+}
+
+func (x *AuditRecord) GetResult() string {
+	if x != nil {
+		return x.Result
+	}
+	return ""
+}
+
+func (x *AuditRecord) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *AuditRecord) GetProcesses() []string {
+	if x != nil {
+		return x.Processes
+	}
+	return nil
+}
+
+type AuditResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Records       []*AuditRecord         `protobuf:"bytes,1,rep,name=records,proto3" json:"records,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AuditResponse) Reset() {
+	*x = AuditResponse{}
+	mi := &file_api_supervisor_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AuditResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuditResponse) ProtoMessage() {}
+
+func (x *AuditResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_supervisor_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuditResponse.ProtoReflect.Descriptor instead.
+func (*AuditResponse) Descriptor() ([]byte, []int) {
+	return file_api_supervisor_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *AuditResponse) GetRecords() []*AuditRecord {
+	if x != nil {
+		return x.Records
+	}
+	return nil
+}
+
+type SignalRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Group string                 `protobuf:"bytes,2,opt,name=group,proto3" json:"group,omitempty"`
+	// signal is a name such as "HUP" or "SIGUSR1"
+	Signal        string `protobuf:"bytes,3,opt,name=signal,proto3" json:"signal,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SignalRequest) Reset() {
+	*x = SignalRequest{}
+	mi := &file_api_supervisor_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SignalRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SignalRequest) ProtoMessage() {}
+
+func (x *SignalRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_supervisor_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SignalRequest.ProtoReflect.Descriptor instead.
+func (*SignalRequest) Descriptor() ([]byte, []int) {
+	return file_api_supervisor_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *SignalRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SignalRequest) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+func (x *SignalRequest) GetSignal() string {
+	if x != nil {
+		return x.Signal
+	}
+	return ""
+}
+
+var File_api_supervisor_proto protoreflect.FileDescriptor
+
+const file_api_supervisor_proto_rawDesc = "" +
+	"\n" +
+	"\x14api/supervisor.proto\x12\x04gosv\x1a\x1egoogle/protobuf/duration.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"P\n" +
+	"\x0eProcessRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05group\x18\x02 \x01(\tR\x05group\x12\x14\n" +
+	"\x05force\x18\x03 \x01(\bR\x05force\"\x0f\n" +
+	"\rStatusRequest\"q\n" +
+	"\bResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x121\n" +
+	"\tprocesses\x18\x03 \x03(\v2\x13.gosv.ProcessStatusR\tprocesses\"\xb8\x03\n" +
+	"\rProcessStatus\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12\x10\n" +
+	"\x03pid\x18\x03 \x01(\x05R\x03pid\x12\x1a\n" +
+	"\brestarts\x18\x04 \x01(\x05R\brestarts\x12\x14\n" +
+	"\x05error\x18\x05 \x01(\tR\x05error\x12#\n" +
+	"\rstart_retries\x18\x06 \x01(\x05R\fstartRetries\x12\x14\n" +
+	"\x05ready\x18\a \x01(\bR\x05ready\x12\x14\n" +
+	"\x05group\x18\b \x01(\tR\x05group\x129\n" +
+	"\n" +
+	"start_time\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\tstartTime\x12\x1b\n" +
+	"\texit_code\x18\n" +
+	" \x01(\x05R\bexitCode\x12!\n" +
+	"\fmax_restarts\x18\v \x01(\x05R\vmaxRestarts\x12@\n" +
+	"\x0erestart_window\x18\f \x01(\v2\x19.google.protobuf.DurationR\rrestartWindow\x12)\n" +
+	"\x05usage\x18\r \x01(\v2\x13.gosv.ResourceUsageR\x05usage\"\xbd\x02\n" +
+	"\rResourceUsage\x12\x1f\n" +
+	"\vcpu_percent\x18\x01 \x01(\x01R\n" +
+	"cpuPercent\x124\n" +
+	"\bcpu_time\x18\x02 \x01(\v2\x19.google.protobuf.DurationR\acpuTime\x12\x1b\n" +
+	"\trss_bytes\x18\x03 \x01(\x04R\brssBytes\x12\x18\n" +
+	"\athreads\x18\x04 \x01(\x05R\athreads\x12\x10\n" +
+	"\x03fds\x18\x05 \x01(\x05R\x03fds\x12\x1d\n" +
+	"\n" +
+	"read_bytes\x18\x06 \x01(\x04R\treadBytes\x12\x1f\n" +
+	"\vwrite_bytes\x18\a \x01(\x04R\n" +
+	"writeBytes\x12\x1c\n" +
+	"\tprocesses\x18\b \x01(\x05R\tprocesses\x12.\n" +
+	"\x04time\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\x04time\"C\n" +
+	"\x0eStatusResponse\x121\n" +
+	"\tprocesses\x18\x01 \x03(\v2\x13.gosv.ProcessStatusR\tprocesses\"\xa3\x01\n" +
+	"\vLogsRequest\x12\x1c\n" +
+	"\tprocesses\x18\x01 \x03(\tR\tprocesses\x12\x14\n" +
+	"\x05level\x18\x02 \x01(\tR\x05level\x12\x18\n" +
+	"\astreams\x18\x03 \x03(\tR\astreams\x120\n" +
+	"\x05since\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x05since\x12\x14\n" +
+	"\x05limit\x18\x05 \x01(\x05R\x05limit\"\xaf\x01\n" +
+	"\tLogRecord\x12.\n" +
+	"\x04time\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\x12\x14\n" +
+	"\x05level\x18\x02 \x01(\tR\x05level\x12\x18\n" +
+	"\aprocess\x18\x03 \x01(\tR\aprocess\x12\x10\n" +
+	"\x03pid\x18\x04 \x01(\x05R\x03pid\x12\x16\n" +
+	"\x06stream\x18\x05 \x01(\tR\x06stream\x12\x18\n" +
+	"\amessage\x18\x06 \x01(\tR\amessage\"9\n" +
+	"\fLogsResponse\x12)\n" +
+	"\arecords\x18\x01 \x03(\v2\x0f.gosv.LogRecordR\arecords\"\xb5\x01\n" +
+	"\x11StreamLogsRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05group\x18\x02 \x01(\tR\x05group\x12\x18\n" +
+	"\astreams\x18\x03 \x03(\tR\astreams\x12\x12\n" +
+	"\x04tail\x18\x04 \x01(\x05R\x04tail\x12\x16\n" +
+	"\x06follow\x18\x05 \x01(\bR\x06follow\x120\n" +
+	"\x05since\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\x05since\"l\n" +
+	"\fWatchRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05group\x18\x02 \x01(\tR\x05group\x12\x16\n" +
+	"\x06states\x18\x03 \x03(\tR\x06states\x12\x1a\n" +
+	"\bsnapshot\x18\x04 \x01(\bR\bsnapshot\"\x96\x02\n" +
+	"\fProcessEvent\x12.\n" +
+	"\x04time\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\x12\x18\n" +
+	"\aprocess\x18\x02 \x01(\tR\aprocess\x12\x14\n" +
+	"\x05group\x18\x03 \x01(\tR\x05group\x12\x12\n" +
+	"\x04from\x18\x04 \x01(\tR\x04from\x12\x0e\n" +
+	"\x02to\x18\x05 \x01(\tR\x02to\x12\x10\n" +
+	"\x03pid\x18\x06 \x01(\x05R\x03pid\x12\x1b\n" +
+	"\texit_code\x18\a \x01(\x05R\bexitCode\x12\x14\n" +
+	"\x05error\x18\b \x01(\tR\x05error\x12\x1a\n" +
+	"\bsnapshot\x18\t \x01(\bR\bsnapshot\x12!\n" +
+	"\fsnapshot_end\x18\n" +
+	" \x01(\bR\vsnapshotEnd\"(\n" +
+	"\rReloadRequest\x12\x17\n" +
+	"\adry_run\x18\x01 \x01(\bR\x06dryRun\"G\n" +
+	"\x14ProcessConfigRequest\x12\x16\n" +
+	"\x06config\x18\x01 \x01(\tR\x06config\x12\x17\n" +
+	"\adry_run\x18\x02 \x01(\bR\x06dryRun\"C\n" +
+	"\x14RemoveProcessRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x17\n" +
+	"\adry_run\x18\x02 \x01(\bR\x06dryRun\"s\n" +
+	"\tChangeSet\x12\x14\n" +
+	"\x05added\x18\x01 \x03(\tR\x05added\x12\x18\n" +
+	"\aupdated\x18\x02 \x03(\tR\aupdated\x12\x18\n" +
+	"\aremoved\x18\x03 \x03(\tR\aremoved\x12\x1c\n" +
+	"\tunchanged\x18\x04 \x03(\tR\tunchanged\"\xa1\x01\n" +
+	"\x0eChangeResponse\x12\x18\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\x12)\n" +
+	"\achanges\x18\x02 \x01(\v2\x0f.gosv.ChangeSetR\achanges\x12\x17\n" +
+	"\adry_run\x18\x03 \x01(\bR\x06dryRun\x121\n" +
+	"\tprocesses\x18\x04 \x03(\v2\x13.gosv.ProcessStatusR\tprocesses\"\xeb\x01\n" +
+	"\fAuditRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05actor\x18\x02 \x01(\tR\x05actor\x12\x16\n" +
+	"\x06action\x18\x03 \x01(\tR\x06action\x120\n" +
+	"\x05since\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x05since\x120\n" +
+	"\x05until\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\x05until\x12\x1f\n" +
+	"\vfailed_only\x18\x06 \x01(\bR\n" +
+	"failedOnly\x12\x14\n" +
+	"\x05limit\x18\a \x01(\x05R\x05limit\"\xff\x01\n" +
+	"\vAuditRecord\x12.\n" +
+	"\x04time\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\x12\x14\n" +
+	"\x05actor\x18\x02 \x01(\tR\x05actor\x12\x16\n" +
+	"\x06source\x18\x03 \x01(\tR\x06source\x12\x16\n" +
+	"\x06action\x18\x04 \x01(\tR\x06action\x12\x16\n" +
+	"\x06target\x18\x05 \x01(\tR\x06target\x12\x16\n" +
+	"\x06detail\x18\x06 \x01(\tR\x06detail\x12\x16\n" +
+	"\x06result\x18\a \x01(\tR\x06result\x12\x14\n" +
+	"\x05error\x18\b \x01(\tR\x05error\x12\x1c\n" +
+	"\tprocesses\x18\t \x03(\tR\tprocesses\"<\n" +
+	"\rAuditResponse\x12+\n" +
+	"\arecords\x18\x01 \x03(\v2\x11.gosv.AuditRecordR\arecords\"Q\n" +
+	"\rSignalRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05group\x18\x02 \x01(\tR\x05group\x12\x16\n" +
+	"\x06signal\x18\x03 \x01(\tR\x06signal2\x95\x06\n" +
+	"\n" +
+	"Supervisor\x126\n" +
+	"\fStartProcess\x12\x14.gosv.ProcessRequest\x1a\x0e.gosv.Response\"\x00\x125\n" +
+	"\vStopProcess\x12\x14.gosv.ProcessRequest\x1a\x0e.gosv.Response\"\x00\x128\n" +
+	"\x0eRestartProcess\x12\x14.gosv.ProcessRequest\x1a\x0e.gosv.Response\"\x00\x128\n" +
+	"\tGetStatus\x12\x13.gosv.StatusRequest\x1a\x14.gosv.StatusResponse\"\x00\x122\n" +
+	"\aGetLogs\x12\x11.gosv.LogsRequest\x1a\x12.gosv.LogsResponse\"\x00\x12:\n" +
+	"\n" +
+	"StreamLogs\x12\x17.gosv.StreamLogsRequest\x1a\x0f.gosv.LogRecord\"\x000\x01\x129\n" +
+	"\vWatchEvents\x12\x12.gosv.WatchRequest\x1a\x12.gosv.ProcessEvent\"\x000\x01\x12;\n" +
+	"\fReloadConfig\x12\x13.gosv.ReloadRequest\x1a\x14.gosv.ChangeResponse\"\x00\x126\n" +
+	"\rSignalProcess\x12\x13.gosv.SignalRequest\x1a\x0e.gosv.Response\"\x00\x12@\n" +
+	"\n" +
+	"AddProcess\x12\x1a.gosv.ProcessConfigRequest\x1a\x14.gosv.ChangeResponse\"\x00\x12C\n" +
+	"\rUpdateProcess\x12\x1a.gosv.ProcessConfigRequest\x1a\x14.gosv.ChangeResponse\"\x00\x12C\n" +
+	"\rRemoveProcess\x12\x1a.gosv.RemoveProcessRequest\x1a\x14.gosv.ChangeResponse\"\x00\x128\n" +
+	"\vGetAuditLog\x12\x12.gosv.AuditRequest\x1a\x13.gosv.AuditResponse\"\x00B!Z\x1fgithub.com/kolkov/gosv/api/gosvb\x06proto3"
 
 var (
 	file_api_supervisor_proto_rawDescOnce sync.Once
@@ -311,29 +1739,82 @@ func file_api_supervisor_proto_rawDescGZIP() []byte {
 	return file_api_supervisor_proto_rawDescData
 }
 
-var file_api_supervisor_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_api_supervisor_proto_msgTypes = make([]protoimpl.MessageInfo, 21)
 var file_api_supervisor_proto_goTypes = []any{
-	(*ProcessRequest)(nil), // 0: gosv.ProcessRequest
-	(*StatusRequest)(nil),  // 1: gosv.StatusRequest
-	(*Response)(nil),       // 2: gosv.Response
-	(*ProcessStatus)(nil),  // 3: gosv.ProcessStatus
-	(*StatusResponse)(nil), // 4: gosv.StatusResponse
+	(*ProcessRequest)(nil),        // 0: gosv.ProcessRequest
+	(*StatusRequest)(nil),         // 1: gosv.StatusRequest
+	(*Response)(nil),              // 2: gosv.Response
+	(*ProcessStatus)(nil),         // 3: gosv.ProcessStatus
+	(*ResourceUsage)(nil),         // 4: gosv.ResourceUsage
+	(*StatusResponse)(nil),        // 5: gosv.StatusResponse
+	(*LogsRequest)(nil),           // 6: gosv.LogsRequest
+	(*LogRecord)(nil),             // 7: gosv.LogRecord
+	(*LogsResponse)(nil),          // 8: gosv.LogsResponse
+	(*StreamLogsRequest)(nil),     // 9: gosv.StreamLogsRequest
+	(*WatchRequest)(nil),          // 10: gosv.WatchRequest
+	(*ProcessEvent)(nil),          // 11: gosv.ProcessEvent
+	(*ReloadRequest)(nil),         // 12: gosv.ReloadRequest
+	(*ProcessConfigRequest)(nil),  // 13: gosv.ProcessConfigRequest
+	(*RemoveProcessRequest)(nil),  // 14: gosv.RemoveProcessRequest
+	(*ChangeSet)(nil),             // 15: gosv.ChangeSet
+	(*ChangeResponse)(nil),        // 16: gosv.ChangeResponse
+	(*AuditRequest)(nil),          // 17: gosv.AuditRequest
+	(*AuditRecord)(nil),           // 18: gosv.AuditRecord
+	(*AuditResponse)(nil),         // 19: gosv.AuditResponse
+	(*SignalRequest)(nil),         // 20: gosv.SignalRequest
+	(*timestamppb.Timestamp)(nil), // 21: google.protobuf.Timestamp
+	(*durationpb.Duration)(nil),   // 22: google.protobuf.Duration
 }
 var file_api_supervisor_proto_depIdxs = []int32{
-	3, // 0: gosv.StatusResponse.processes:type_name -> gosv.ProcessStatus
-	0, // 1: gosv.Supervisor.StartProcess:input_type -> gosv.ProcessRequest
-	0, // 2: gosv.Supervisor.StopProcess:input_type -> gosv.ProcessRequest
-	0, // 3: gosv.Supervisor.RestartProcess:input_type -> gosv.ProcessRequest
-	1, // 4: gosv.Supervisor.GetStatus:input_type -> gosv.StatusRequest
-	2, // 5: gosv.Supervisor.StartProcess:output_type -> gosv.Response
-	2, // 6: gosv.Supervisor.StopProcess:output_type -> gosv.Response
-	2, // 7: gosv.Supervisor.RestartProcess:output_type -> gosv.Response
-	4, // 8: gosv.Supervisor.GetStatus:output_type -> gosv.StatusResponse
-	5, // [5:9] is the sub-list for method output_type
-	1, // [1:5] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	3,  // 0: gosv.Response.processes:type_name -> gosv.ProcessStatus
+	21, // 1: gosv.ProcessStatus.start_time:type_name -> google.protobuf.Timestamp
+	22, // 2: gosv.ProcessStatus.restart_window:type_name -> google.protobuf.Duration
+	4,  // 3: gosv.ProcessStatus.usage:type_name -> gosv.ResourceUsage
+	22, // 4: gosv.ResourceUsage.cpu_time:type_name -> google.protobuf.Duration
+	21, // 5: gosv.ResourceUsage.time:type_name -> google.protobuf.Timestamp
+	3,  // 6: gosv.StatusResponse.processes:type_name -> gosv.ProcessStatus
+	21, // 7: gosv.LogsRequest.since:type_name -> google.protobuf.Timestamp
+	21, // 8: gosv.LogRecord.time:type_name -> google.protobuf.Timestamp
+	7,  // 9: gosv.LogsResponse.records:type_name -> gosv.LogRecord
+	21, // 10: gosv.StreamLogsRequest.since:type_name -> google.protobuf.Timestamp
+	21, // 11: gosv.ProcessEvent.time:type_name -> google.protobuf.Timestamp
+	15, // 12: gosv.ChangeResponse.changes:type_name -> gosv.ChangeSet
+	3,  // 13: gosv.ChangeResponse.processes:type_name -> gosv.ProcessStatus
+	21, // 14: gosv.AuditRequest.since:type_name -> google.protobuf.Timestamp
+	21, // 15: gosv.AuditRequest.until:type_name -> google.protobuf.Timestamp
+	21, // 16: gosv.AuditRecord.time:type_name -> google.protobuf.Timestamp
+	18, // 17: gosv.AuditResponse.records:type_name -> gosv.AuditRecord
+	0,  // 18: gosv.Supervisor.StartProcess:input_type -> gosv.ProcessRequest
+	0,  // 19: gosv.Supervisor.StopProcess:input_type -> gosv.ProcessRequest
+	0,  // 20: gosv.Supervisor.RestartProcess:input_type -> gosv.ProcessRequest
+	1,  // 21: gosv.Supervisor.GetStatus:input_type -> gosv.StatusRequest
+	6,  // 22: gosv.Supervisor.GetLogs:input_type -> gosv.LogsRequest
+	9,  // 23: gosv.Supervisor.StreamLogs:input_type -> gosv.StreamLogsRequest
+	10, // 24: gosv.Supervisor.WatchEvents:input_type -> gosv.WatchRequest
+	12, // 25: gosv.Supervisor.ReloadConfig:input_type -> gosv.ReloadRequest
+	20, // 26: gosv.Supervisor.SignalProcess:input_type -> gosv.SignalRequest
+	13, // 27: gosv.Supervisor.AddProcess:input_type -> gosv.ProcessConfigRequest
+	13, // 28: gosv.Supervisor.UpdateProcess:input_type -> gosv.ProcessConfigRequest
+	14, // 29: gosv.Supervisor.RemoveProcess:input_type -> gosv.RemoveProcessRequest
+	17, // 30: gosv.Supervisor.GetAuditLog:input_type -> gosv.AuditRequest
+	2,  // 31: gosv.Supervisor.StartProcess:output_type -> gosv.Response
+	2,  // 32: gosv.Supervisor.StopProcess:output_type -> gosv.Response
+	2,  // 33: gosv.Supervisor.RestartProcess:output_type -> gosv.Response
+	5,  // 34: gosv.Supervisor.GetStatus:output_type -> gosv.StatusResponse
+	8,  // 35: gosv.Supervisor.GetLogs:output_type -> gosv.LogsResponse
+	7,  // 36: gosv.Supervisor.StreamLogs:output_type -> gosv.LogRecord
+	11, // 37: gosv.Supervisor.WatchEvents:output_type -> gosv.ProcessEvent
+	16, // 38: gosv.Supervisor.ReloadConfig:output_type -> gosv.ChangeResponse
+	2,  // 39: gosv.Supervisor.SignalProcess:output_type -> gosv.Response
+	16, // 40: gosv.Supervisor.AddProcess:output_type -> gosv.ChangeResponse
+	16, // 41: gosv.Supervisor.UpdateProcess:output_type -> gosv.ChangeResponse
+	16, // 42: gosv.Supervisor.RemoveProcess:output_type -> gosv.ChangeResponse
+	19, // 43: gosv.Supervisor.GetAuditLog:output_type -> gosv.AuditResponse
+	31, // [31:44] is the sub-list for method output_type
+	18, // [18:31] is the sub-list for method input_type
+	18, // [18:18] is the sub-list for extension type_name
+	18, // [18:18] is the sub-list for extension extendee
+	0,  // [0:18] is the sub-list for field type_name
 }
 
 func init() { file_api_supervisor_proto_init() }
@@ -347,7 +1828,7 @@ func file_api_supervisor_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_supervisor_proto_rawDesc), len(file_api_supervisor_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   5,
+			NumMessages:   21,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -23,6 +23,15 @@ const (
 	Supervisor_StopProcess_FullMethodName    = "/gosv.Supervisor/StopProcess"
 	Supervisor_RestartProcess_FullMethodName = "/gosv.Supervisor/RestartProcess"
 	Supervisor_GetStatus_FullMethodName      = "/gosv.Supervisor/GetStatus"
+	Supervisor_GetLogs_FullMethodName        = "/gosv.Supervisor/GetLogs"
+	Supervisor_StreamLogs_FullMethodName     = "/gosv.Supervisor/StreamLogs"
+	Supervisor_WatchEvents_FullMethodName    = "/gosv.Supervisor/WatchEvents"
+	Supervisor_ReloadConfig_FullMethodName   = "/gosv.Supervisor/ReloadConfig"
+	Supervisor_SignalProcess_FullMethodName  = "/gosv.Supervisor/SignalProcess"
+	Supervisor_AddProcess_FullMethodName     = "/gosv.Supervisor/AddProcess"
+	Supervisor_UpdateProcess_FullMethodName  = "/gosv.Supervisor/UpdateProcess"
+	Supervisor_RemoveProcess_FullMethodName  = "/gosv.Supervisor/RemoveProcess"
+	Supervisor_GetAuditLog_FullMethodName    = "/gosv.Supervisor/GetAuditLog"
 )
 
 // SupervisorClient is the client API for Supervisor service.
@@ -33,6 +42,23 @@ type SupervisorClient interface {
 	StopProcess(ctx context.Context, in *ProcessRequest, opts ...grpc.CallOption) (*Response, error)
 	RestartProcess(ctx context.Context, in *ProcessRequest, opts ...grpc.CallOption) (*Response, error)
 	GetStatus(ctx context.Context, in *StatusRequest, opts ...grpc.CallOption) (*StatusResponse, error)
+	GetLogs(ctx context.Context, in *LogsRequest, opts ...grpc.CallOption) (*LogsResponse, error)
+	StreamLogs(ctx context.Context, in *StreamLogsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[LogRecord], error)
+	WatchEvents(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ProcessEvent], error)
+	// ReloadConfig makes the daemon re-read its config file. Only programs
+	// whose settings changed are restarted.
+	ReloadConfig(ctx context.Context, in *ReloadRequest, opts ...grpc.CallOption) (*ChangeResponse, error)
+	// SignalProcess sends a signal such as HUP or USR1 to running processes.
+	SignalProcess(ctx context.Context, in *SignalRequest, opts ...grpc.CallOption) (*Response, error)
+	// AddProcess, UpdateProcess and RemoveProcess change the running
+	// configuration without touching the config file; the next ReloadConfig
+	// undoes them.
+	AddProcess(ctx context.Context, in *ProcessConfigRequest, opts ...grpc.CallOption) (*ChangeResponse, error)
+	UpdateProcess(ctx context.Context, in *ProcessConfigRequest, opts ...grpc.CallOption) (*ChangeResponse, error)
+	RemoveProcess(ctx context.Context, in *RemoveProcessRequest, opts ...grpc.CallOption) (*ChangeResponse, error)
+	// GetAuditLog returns recorded control actions: who started, stopped,
+	// restarted, signalled or reloaded what, and how it ended.
+	GetAuditLog(ctx context.Context, in *AuditRequest, opts ...grpc.CallOption) (*AuditResponse, error)
 }
 
 type supervisorClient struct {
@@ -83,6 +109,114 @@ func (c *supervisorClient) GetStatus(ctx context.Context, in *StatusRequest, opt
 	return out, nil
 }
 
+func (c *supervisorClient) GetLogs(ctx context.Context, in *LogsRequest, opts ...grpc.CallOption) (*LogsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LogsResponse)
+	err := c.cc.Invoke(ctx, Supervisor_GetLogs_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *supervisorClient) StreamLogs(ctx context.Context, in *StreamLogsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[LogRecord], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Supervisor_ServiceDesc.Streams[0], Supervisor_StreamLogs_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamLogsRequest, LogRecord]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Supervisor_StreamLogsClient = grpc.ServerStreamingClient[LogRecord]
+
+func (c *supervisorClient) WatchEvents(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ProcessEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Supervisor_ServiceDesc.Streams[1], Supervisor_WatchEvents_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchRequest, ProcessEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Supervisor_WatchEventsClient = grpc.ServerStreamingClient[ProcessEvent]
+
+func (c *supervisorClient) ReloadConfig(ctx context.Context, in *ReloadRequest, opts ...grpc.CallOption) (*ChangeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ChangeResponse)
+	err := c.cc.Invoke(ctx, Supervisor_ReloadConfig_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *supervisorClient) SignalProcess(ctx context.Context, in *SignalRequest, opts ...grpc.CallOption) (*Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Response)
+	err := c.cc.Invoke(ctx, Supervisor_SignalProcess_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *supervisorClient) AddProcess(ctx context.Context, in *ProcessConfigRequest, opts ...grpc.CallOption) (*ChangeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ChangeResponse)
+	err := c.cc.Invoke(ctx, Supervisor_AddProcess_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *supervisorClient) UpdateProcess(ctx context.Context, in *ProcessConfigRequest, opts ...grpc.CallOption) (*ChangeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ChangeResponse)
+	err := c.cc.Invoke(ctx, Supervisor_UpdateProcess_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *supervisorClient) RemoveProcess(ctx context.Context, in *RemoveProcessRequest, opts ...grpc.CallOption) (*ChangeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ChangeResponse)
+	err := c.cc.Invoke(ctx, Supervisor_RemoveProcess_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *supervisorClient) GetAuditLog(ctx context.Context, in *AuditRequest, opts ...grpc.CallOption) (*AuditResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AuditResponse)
+	err := c.cc.Invoke(ctx, Supervisor_GetAuditLog_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // SupervisorServer is the server API for Supervisor service.
 // All implementations must embed UnimplementedSupervisorServer
 // for forward compatibility.
@@ -91,6 +225,23 @@ type SupervisorServer interface {
 	StopProcess(context.Context, *ProcessRequest) (*Response, error)
 	RestartProcess(context.Context, *ProcessRequest) (*Response, error)
 	GetStatus(context.Context, *StatusRequest) (*StatusResponse, error)
+	GetLogs(context.Context, *LogsRequest) (*LogsResponse, error)
+	StreamLogs(*StreamLogsRequest, grpc.ServerStreamingServer[LogRecord]) error
+	WatchEvents(*WatchRequest, grpc.ServerStreamingServer[ProcessEvent]) error
+	// ReloadConfig makes the daemon re-read its config file. Only programs
+	// whose settings changed are restarted.
+	ReloadConfig(context.Context, *ReloadRequest) (*ChangeResponse, error)
+	// SignalProcess sends a signal such as HUP or USR1 to running processes.
+	SignalProcess(context.Context, *SignalRequest) (*Response, error)
+	// AddProcess, UpdateProcess and RemoveProcess change the running
+	// configuration without touching the config file; the next ReloadConfig
+	// undoes them.
+	AddProcess(context.Context, *ProcessConfigRequest) (*ChangeResponse, error)
+	UpdateProcess(context.Context, *ProcessConfigRequest) (*ChangeResponse, error)
+	RemoveProcess(context.Context, *RemoveProcessRequest) (*ChangeResponse, error)
+	// GetAuditLog returns recorded control actions: who started, stopped,
+	// restarted, signalled or reloaded what, and how it ended.
+	GetAuditLog(context.Context, *AuditRequest) (*AuditResponse, error)
 	mustEmbedUnimplementedSupervisorServer()
 }
 
@@ -113,6 +264,33 @@ func (UnimplementedSupervisorServer) RestartProcess(context.Context, *ProcessReq
 func (UnimplementedSupervisorServer) GetStatus(context.Context, *StatusRequest) (*StatusResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetStatus not implemented")
 }
+func (UnimplementedSupervisorServer) GetLogs(context.Context, *LogsRequest) (*LogsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLogs not implemented")
+}
+func (UnimplementedSupervisorServer) StreamLogs(*StreamLogsRequest, grpc.ServerStreamingServer[LogRecord]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamLogs not implemented")
+}
+func (UnimplementedSupervisorServer) WatchEvents(*WatchRequest, grpc.ServerStreamingServer[ProcessEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchEvents not implemented")
+}
+func (UnimplementedSupervisorServer) ReloadConfig(context.Context, *ReloadRequest) (*ChangeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReloadConfig not implemented")
+}
+func (UnimplementedSupervisorServer) SignalProcess(context.Context, *SignalRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SignalProcess not implemented")
+}
+func (UnimplementedSupervisorServer) AddProcess(context.Context, *ProcessConfigRequest) (*ChangeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddProcess not implemented")
+}
+func (UnimplementedSupervisorServer) UpdateProcess(context.Context, *ProcessConfigRequest) (*ChangeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateProcess not implemented")
+}
+func (UnimplementedSupervisorServer) RemoveProcess(context.Context, *RemoveProcessRequest) (*ChangeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveProcess not implemented")
+}
+func (UnimplementedSupervisorServer) GetAuditLog(context.Context, *AuditRequest) (*AuditResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAuditLog not implemented")
+}
 func (UnimplementedSupervisorServer) mustEmbedUnimplementedSupervisorServer() {}
 func (UnimplementedSupervisorServer) testEmbeddedByValue()                    {}
 
@@ -206,6 +384,154 @@ func _Supervisor_GetStatus_Handler(srv interface{}, ctx context.Context, dec fun
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Supervisor_GetLogs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LogsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SupervisorServer).GetLogs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Supervisor_GetLogs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SupervisorServer).GetLogs(ctx, req.(*LogsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Supervisor_StreamLogs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamLogsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SupervisorServer).StreamLogs(m, &grpc.GenericServerStream[StreamLogsRequest, LogRecord]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Supervisor_StreamLogsServer = grpc.ServerStreamingServer[LogRecord]
+
+func _Supervisor_WatchEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SupervisorServer).WatchEvents(m, &grpc.GenericServerStream[WatchRequest, ProcessEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Supervisor_WatchEventsServer = grpc.ServerStreamingServer[ProcessEvent]
+
+func _Supervisor_ReloadConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReloadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SupervisorServer).ReloadConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Supervisor_ReloadConfig_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SupervisorServer).ReloadConfig(ctx, req.(*ReloadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Supervisor_SignalProcess_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SignalRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SupervisorServer).SignalProcess(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Supervisor_SignalProcess_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SupervisorServer).SignalProcess(ctx, req.(*SignalRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Supervisor_AddProcess_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProcessConfigRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SupervisorServer).AddProcess(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Supervisor_AddProcess_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SupervisorServer).AddProcess(ctx, req.(*ProcessConfigRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Supervisor_UpdateProcess_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProcessConfigRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SupervisorServer).UpdateProcess(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Supervisor_UpdateProcess_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SupervisorServer).UpdateProcess(ctx, req.(*ProcessConfigRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Supervisor_RemoveProcess_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveProcessRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SupervisorServer).RemoveProcess(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Supervisor_RemoveProcess_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SupervisorServer).RemoveProcess(ctx, req.(*RemoveProcessRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Supervisor_GetAuditLog_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AuditRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SupervisorServer).GetAuditLog(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Supervisor_GetAuditLog_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SupervisorServer).GetAuditLog(ctx, req.(*AuditRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Supervisor_ServiceDesc is the grpc.ServiceDesc for Supervisor service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -229,7 +555,46 @@ var Supervisor_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetStatus",
 			Handler:    _Supervisor_GetStatus_Handler,
 		},
+		{
+			MethodName: "GetLogs",
+			Handler:    _Supervisor_GetLogs_Handler,
+		},
+		{
+			MethodName: "ReloadConfig",
+			Handler:    _Supervisor_ReloadConfig_Handler,
+		},
+		{
+			MethodName: "SignalProcess",
+			Handler:    _Supervisor_SignalProcess_Handler,
+		},
+		{
+			MethodName: "AddProcess",
+			Handler:    _Supervisor_AddProcess_Handler,
+		},
+		{
+			MethodName: "UpdateProcess",
+			Handler:    _Supervisor_UpdateProcess_Handler,
+		},
+		{
+			MethodName: "RemoveProcess",
+			Handler:    _Supervisor_RemoveProcess_Handler,
+		},
+		{
+			MethodName: "GetAuditLog",
+			Handler:    _Supervisor_GetAuditLog_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamLogs",
+			Handler:       _Supervisor_StreamLogs_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchEvents",
+			Handler:       _Supervisor_WatchEvents_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "api/supervisor.proto",
 }
@@ -2,24 +2,31 @@ package cmd
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"github.com/kolkov/gosv/api/gosv"
 	"log"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
 )
 
-func getClient() (gosv.SupervisorClient, *grpc.ClientConn) {
-	// Конфигурируемый адрес (можно вынести в флаги)
-	conn, err := grpc.Dial("localhost:50051", grpc.WithInsecure())
+// getClient connects to the gRPC API at addr, over TLS unless tlsCfg is nil.
+func getClient(addr string, tlsCfg *tls.Config) (gosv.SupervisorClient, *grpc.ClientConn) {
+	creds := insecure.NewCredentials()
+	if tlsCfg != nil {
+		creds = credentials.NewTLS(tlsCfg)
+	}
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(creds))
 	if err != nil {
 		log.Fatalf("Failed to connect: %v", err)
 	}
 	return gosv.NewSupervisorClient(conn), conn
 }
 
-func startProcessRemote(name string) {
-	client, conn := getClient()
+func startProcessRemote(addr string, tlsCfg *tls.Config, name string) {
+	client, conn := getClient(addr, tlsCfg)
 	defer conn.Close()
 
 	resp, err := client.StartProcess(context.Background(), &gosv.ProcessRequest{Name: name})
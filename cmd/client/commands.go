@@ -0,0 +1,466 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/kolkov/gosv/api/gosv"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// parseArgs parses the flags of a command and checks the number of
+// positional arguments.
+func parseArgs(fs *flag.FlagSet, args []string, minArgs, maxArgs int) bool {
+	fs.SetOutput(os.Stderr)
+	if err := fs.Parse(args); err != nil {
+		return false
+	}
+	if fs.NArg() < minArgs || fs.NArg() > maxArgs {
+		fmt.Fprintf(os.Stderr, "Error: %s: wrong number of arguments\nRun with -h for usage.\n", fs.Name())
+		return false
+	}
+	return true
+}
+
+// fail reports an RPC error and returns the exit status for it.
+func fail(err error) int {
+	fmt.Fprintf(os.Stderr, "Error: %s\n", errorMessage(err))
+	return exitCode(err)
+}
+
+func (c *cli) status(args []string) int {
+	fs := flag.NewFlagSet("status", flag.ContinueOnError)
+	if !parseArgs(fs, args, 0, 1) {
+		return exitUsage
+	}
+	name := fs.Arg(0)
+
+	ctx, cancel := c.context()
+	defer cancel()
+	resp, err := c.client.GetStatus(ctx, &gosv.StatusRequest{})
+	if err != nil {
+		return fail(err)
+	}
+
+	var views []processView
+	for _, ps := range resp.Processes {
+		if matches(ps, name) {
+			views = append(views, newProcessView(ps))
+		}
+	}
+	if name != "" && len(views) == 0 {
+		fmt.Fprintf(os.Stderr, "Error: process not found: %s\n", name)
+		return exitFailed
+	}
+	// Как в статусе демона: по группам, процессы без группы в конце
+	slices.SortFunc(views, func(a, b processView) int {
+		if (a.Group == "") != (b.Group == "") {
+			if a.Group == "" {
+				return 1
+			}
+			return -1
+		}
+		return cmp.Or(cmp.Compare(a.Group, b.Group), cmp.Compare(a.Name, b.Name))
+	})
+
+	c.out.print(views, func(w *tabwriter.Writer) {
+		fmt.Fprintln(w, "NAME\tGROUP\tSTATUS\tPID\tREADY\tUPTIME\tRESTARTS\tCPU\tMEM\tERROR")
+		for _, v := range views {
+			pid, restarts := "-", fmt.Sprint(v.Restarts)
+			if v.PID != 0 {
+				pid = fmt.Sprint(v.PID)
+			}
+			if v.MaxRestarts >= 0 {
+				restarts += fmt.Sprintf("/%d", v.MaxRestarts)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%v\t%s\t%s\t%s\t%s\t%s\n",
+				v.Name, cmp.Or(v.Group, "-"), v.Status, pid, v.Ready, v.uptime(), restarts, v.cpu(), v.memory(), v.Error)
+		}
+	})
+	return exitOK
+}
+
+// matches selects processes like the daemon resolves names: a process or
+// instance, all instances of a program, or a group as "group:*".
+func matches(ps *gosv.ProcessStatus, name string) bool {
+	if name == "" || ps.Name == name {
+		return true
+	}
+	base := strings.TrimSuffix(name, ":*")
+	return ps.Name == base || strings.HasPrefix(ps.Name, base+":") || ps.Group == base
+}
+
+func (c *cli) control(command string, args []string) int {
+	fs := flag.NewFlagSet(command, flag.ContinueOnError)
+	var force *bool
+	if command == "start" {
+		force = fs.Bool("force", false, "Restart processes that are already running")
+	}
+	if !parseArgs(fs, args, 1, 1) {
+		return exitUsage
+	}
+
+	ctx, cancel := c.context()
+	defer cancel()
+
+	req := &gosv.ProcessRequest{Name: fs.Arg(0)}
+	if force != nil {
+		req.Force = *force
+	}
+	var resp *gosv.Response
+	var err error
+	switch command {
+	case "start":
+		resp, err = c.client.StartProcess(ctx, req)
+	case "stop":
+		resp, err = c.client.StopProcess(ctx, req)
+	case "restart":
+		resp, err = c.client.RestartProcess(ctx, req)
+	}
+	if err != nil {
+		return fail(err)
+	}
+	return c.out.result(fs.Arg(0), resp)
+}
+
+func (c *cli) signal(args []string) int {
+	fs := flag.NewFlagSet("signal", flag.ContinueOnError)
+	if !parseArgs(fs, args, 2, 2) {
+		return exitUsage
+	}
+
+	ctx, cancel := c.context()
+	defer cancel()
+	resp, err := c.client.SignalProcess(ctx, &gosv.SignalRequest{
+		Signal: fs.Arg(0),
+		Name:   fs.Arg(1),
+	})
+	if err != nil {
+		return fail(err)
+	}
+	return c.out.result(fs.Arg(1), resp)
+}
+
+func (c *cli) reload(args []string) int {
+	fs := flag.NewFlagSet("reload", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "Only show what would change")
+	if !parseArgs(fs, args, 0, 0) {
+		return exitUsage
+	}
+
+	ctx, cancel := c.context()
+	defer cancel()
+	resp, err := c.client.ReloadConfig(ctx, &gosv.ReloadRequest{DryRun: *dryRun})
+	if err != nil {
+		return fail(err)
+	}
+	c.out.changes(resp)
+	return exitOK
+}
+
+// configure runs add and update, which read a process entry in config
+// file YAML from a file or, with "-", from stdin.
+func (c *cli) configure(command string, args []string) int {
+	fs := flag.NewFlagSet(command, flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "Only show what would change")
+	if !parseArgs(fs, args, 1, 1) {
+		return exitUsage
+	}
+
+	var data []byte
+	var err error
+	if fs.Arg(0) == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(fs.Arg(0))
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+
+	ctx, cancel := c.context()
+	defer cancel()
+	req := &gosv.ProcessConfigRequest{Config: string(data), DryRun: *dryRun}
+	var resp *gosv.ChangeResponse
+	if command == "add" {
+		resp, err = c.client.AddProcess(ctx, req)
+	} else {
+		resp, err = c.client.UpdateProcess(ctx, req)
+	}
+	if err != nil {
+		return fail(err)
+	}
+	c.out.changes(resp)
+	return exitOK
+}
+
+func (c *cli) remove(args []string) int {
+	fs := flag.NewFlagSet("remove", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "Only show what would change")
+	if !parseArgs(fs, args, 1, 1) {
+		return exitUsage
+	}
+
+	ctx, cancel := c.context()
+	defer cancel()
+	resp, err := c.client.RemoveProcess(ctx, &gosv.RemoveProcessRequest{Name: fs.Arg(0), DryRun: *dryRun})
+	if err != nil {
+		return fail(err)
+	}
+	c.out.changes(resp)
+	return exitOK
+}
+
+func (c *cli) groups(args []string) int {
+	fs := flag.NewFlagSet("groups", flag.ContinueOnError)
+	if !parseArgs(fs, args, 0, 0) {
+		return exitUsage
+	}
+
+	ctx, cancel := c.context()
+	defer cancel()
+	resp, err := c.client.GetStatus(ctx, &gosv.StatusRequest{})
+	if err != nil {
+		return fail(err)
+	}
+
+	index := make(map[string]*groupView)
+	var views []*groupView
+	for _, ps := range resp.Processes {
+		if ps.Group == "" {
+			continue
+		}
+		g, ok := index[ps.Group]
+		if !ok {
+			g = &groupView{Name: ps.Group}
+			index[ps.Group] = g
+			views = append(views, g)
+		}
+		g.Members = append(g.Members, ps.Name)
+		switch ps.Status {
+		case "running":
+			g.Running++
+		case "failed", "fatal":
+			g.Failed++
+		}
+	}
+	slices.SortFunc(views, func(a, b *groupView) int { return cmp.Compare(a.Name, b.Name) })
+	for _, g := range views {
+		slices.Sort(g.Members)
+	}
+
+	c.out.print(views, func(w *tabwriter.Writer) {
+		fmt.Fprintln(w, "GROUP\tRUNNING\tFAILED\tMEMBERS")
+		for _, g := range views {
+			fmt.Fprintf(w, "%s:*\t%d/%d\t%d\t%s\n",
+				g.Name, g.Running, len(g.Members), g.Failed, strings.Join(g.Members, ", "))
+		}
+	})
+	return exitOK
+}
+
+// audit shows who did what, newest last.
+func (c *cli) audit(args []string) int {
+	fs := flag.NewFlagSet("audit", flag.ContinueOnError)
+	limit := fs.Int("n", 50, "Number of most recent records to show, 0 for all")
+	actor := fs.String("actor", "", "Only actions of actors containing this, e.g. a token or user name")
+	action := fs.String("action", "", "Only this action: start, stop, restart, signal, reload, add, update, remove")
+	since := fs.String("since", "", "Only records newer than a duration (24h) or RFC 3339 time")
+	until := fs.String("until", "", "Only records older than a duration or RFC 3339 time")
+	failed := fs.Bool("failed", false, "Only failed and denied actions")
+	if !parseArgs(fs, args, 0, 1) {
+		return exitUsage
+	}
+
+	req := &gosv.AuditRequest{
+		Name:       fs.Arg(0),
+		Actor:      *actor,
+		Action:     *action,
+		FailedOnly: *failed,
+		Limit:      int32(*limit),
+	}
+	for _, f := range []struct {
+		name, value string
+		dst         **timestamppb.Timestamp
+	}{{"since", *since, &req.Since}, {"until", *until, &req.Until}} {
+		if f.value == "" {
+			continue
+		}
+		t, err := parseTime(f.name, f.value)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return exitUsage
+		}
+		*f.dst = timestamppb.New(t)
+	}
+
+	ctx, cancel := c.context()
+	defer cancel()
+	resp, err := c.client.GetAuditLog(ctx, req)
+	if err != nil {
+		return fail(err)
+	}
+
+	views := make([]auditView, 0, len(resp.Records))
+	for _, r := range resp.Records {
+		views = append(views, newAuditView(r))
+	}
+	c.out.print(views, func(w *tabwriter.Writer) {
+		fmt.Fprintln(w, "TIME\tACTOR\tSOURCE\tACTION\tTARGET\tRESULT\tERROR")
+		for _, v := range views {
+			action := v.Action
+			if v.Detail != "" {
+				action += " " + v.Detail
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", v.Time.Format(timeFormat),
+				v.Actor, v.Source, action, cmp.Or(v.Target, "-"), v.Result, v.Error)
+		}
+	})
+	return exitOK
+}
+
+func (c *cli) logs(args []string) int {
+	fs := flag.NewFlagSet("logs", flag.ContinueOnError)
+	follow := fs.Bool("f", false, "Follow new records")
+	tail := fs.Int("n", 20, "Number of buffered records to show, -1 for all")
+	streams := fs.String("s", "", "Comma-separated streams: supervisor, stdout, stderr")
+	since := fs.String("since", "", "Only records newer than a duration (10m) or RFC 3339 time")
+	if !parseArgs(fs, args, 0, 1) {
+		return exitUsage
+	}
+
+	req := &gosv.StreamLogsRequest{
+		Name:   fs.Arg(0),
+		Tail:   int32(*tail),
+		Follow: *follow,
+	}
+	if *streams != "" {
+		req.Streams = strings.Split(*streams, ",")
+	}
+	if *since != "" {
+		t, err := parseTime("since", *since)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return exitUsage
+		}
+		req.Since = timestamppb.New(t)
+	}
+
+	// Ctrl+C завершает follow-режим без ошибки
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if !*follow {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	stream, err := c.client.StreamLogs(ctx, req)
+	if err != nil {
+		return fail(err)
+	}
+	for {
+		rec, err := stream.Recv()
+		if err == io.EOF || (*follow && ctx.Err() != nil) {
+			return exitOK
+		}
+		if err != nil {
+			return fail(err)
+		}
+		c.out.item(newLogView(rec), formatRecord(rec))
+	}
+}
+
+// watch prints status transitions. With -until it returns 0 once every
+// selected process is in the wanted state, and 1 if one of them becomes
+// fatal first or the timeout expires.
+func (c *cli) watch(args []string) int {
+	fs := flag.NewFlagSet("watch", flag.ContinueOnError)
+	until := fs.String("until", "", "Exit once all selected processes are in this state")
+	timeout := fs.Duration("timeout", 0, "Give up after this long (0 waits forever)")
+	if !parseArgs(fs, args, 0, 1) {
+		return exitUsage
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	stream, err := c.client.WatchEvents(ctx, &gosv.WatchRequest{
+		Name:     fs.Arg(0),
+		Snapshot: *until != "",
+	})
+	if err != nil {
+		return fail(err)
+	}
+
+	// Текущие состояния отслеживаемых процессов, известные из снимка
+	states := make(map[string]string)
+	snapshotDone := false
+	for {
+		ev, err := stream.Recv()
+		if err != nil {
+			if ctx.Err() == context.DeadlineExceeded {
+				fmt.Fprintf(os.Stderr, "Timed out waiting for state %s\n", *until)
+				return exitFailed
+			}
+			if err == io.EOF || ctx.Err() != nil {
+				return exitOK
+			}
+			return fail(err)
+		}
+
+		if ev.Snapshot {
+			states[ev.Process] = ev.To
+			snapshotDone = ev.SnapshotEnd
+		} else if _, tracked := states[ev.Process]; tracked || *until == "" {
+			states[ev.Process] = ev.To
+		}
+		c.out.item(newEventView(ev), formatEvent(ev))
+
+		if *until == "" {
+			continue
+		}
+		if ev.To == "fatal" && *until != "fatal" {
+			fmt.Fprintf(os.Stderr, "Process %s is fatal\n", ev.Process)
+			return exitFailed
+		}
+		if snapshotDone && allInState(states, *until) {
+			return exitOK
+		}
+	}
+}
+
+func allInState(states map[string]string, want string) bool {
+	for _, st := range states {
+		if st != want {
+			return false
+		}
+	}
+	return true
+}
+
+func parseTime(flagName, s string) (time.Time, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid -%s %q: want a duration or RFC 3339 time", flagName, s)
+	}
+	return t, nil
+}
@@ -2,71 +2,306 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"flag"
 	"fmt"
-	"log"
+	"net"
 	"os"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/kolkov/gosv/api/gosv"
+	"github.com/kolkov/gosv/internal/api"
+	"github.com/kolkov/gosv/internal/config"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
 )
 
+// Exit codes for scripts.
+const (
+	exitOK          = 0
+	exitFailed      = 1 // операция не удалась
+	exitUsage       = 2
+	exitUnavailable = 3 // демон недоступен
+	exitDenied      = 4 // нет или не подходит токен
+)
+
+const usage = `Usage: client [flags] <command> [args]
+
+Commands:
+  status [name]            show processes, optionally only a program or group (ingest:*)
+  start [-force] <name>    start a process, program, instance (worker:01) or group (ingest:*);
+                           -force restarts it if it is already running
+  stop <name>              stop a process, program, instance or group
+  restart <name>           restart a process, program, instance or group
+  signal <signal> <name>   send a signal such as HUP or USR1 to running processes
+  groups                   list groups with their members
+  reload [-dry-run]        make the daemon re-read its config file; only changed
+                           programs are restarted, -dry-run only shows the changes
+  add [-dry-run] <file>    add a program from a processes entry in YAML ("-" for stdin)
+  update [-dry-run] <file> replace the settings of a program, restarting it if running
+  remove [-dry-run] <name> stop and drop a program
+  logs [-f] [-n N] [-s stdout,stderr] [-since 10m] [name]
+                           show recent log records, -f to follow
+  watch [-until state] [-timeout 5m] [name]
+                           print status changes; with -until wait until all selected
+                           processes reach state
+  audit [-n N] [-actor who] [-action stop] [-since 24h] [-until 1h] [-failed] [name]
+                           show who started, stopped, restarted, signalled or reloaded what
+
+Exit status: 0 on success, 1 if the operation failed (unknown process, already
+running, not running, failed to start), 2 on usage errors, 3 if the daemon
+cannot be reached, 4 if the token is missing or not allowed to do that.
+
+Flags:
+`
+
+var commands = []string{
+	"status", "start", "stop", "restart", "signal", "groups",
+	"reload", "add", "update", "remove", "logs", "watch", "audit",
+}
+
+// options holds the global flags.
+type options struct {
+	addr    string
+	config  string
+	timeout time.Duration
+	output  string
+
+	tls           bool
+	tlsCA         string
+	tlsCert       string
+	tlsKey        string
+	tlsServerName string
+	tlsSkipVerify bool
+
+	token     string
+	tokenFile string
+}
+
 func main() {
-	if len(os.Args) < 3 {
-		fmt.Println("Usage: client.exe <server:port> <command> [args]")
-		fmt.Println("Commands:")
-		fmt.Println("  status   - get processes status")
-		fmt.Println("  start <name> - start process")
-		fmt.Println("  stop <name>  - stop process")
-		return
+	var opts options
+	flag.StringVar(&opts.addr, "addr", "",
+		"Daemon address: host:port, or unix:<path> for the control socket (default: the control socket of -c)")
+	flag.StringVar(&opts.config, "c", "gsv.yaml", "Config file of the daemon, to find its control socket")
+	flag.DurationVar(&opts.timeout, "timeout", 30*time.Second, "Timeout for a single request")
+	flag.StringVar(&opts.output, "o", "table", "Output format: table, json or yaml")
+	flag.BoolVar(&opts.tls, "tls", false, "Connect with TLS")
+	flag.StringVar(&opts.tlsCA, "tls-ca", "", "CA certificate to verify the server with (implies -tls)")
+	flag.StringVar(&opts.tlsCert, "tls-cert", "", "Client certificate for mutual TLS (implies -tls)")
+	flag.StringVar(&opts.tlsKey, "tls-key", "", "Client certificate key for mutual TLS")
+	flag.StringVar(&opts.tlsServerName, "tls-server-name", "", "Server name to verify instead of the address host")
+	flag.BoolVar(&opts.tlsSkipVerify, "tls-skip-verify", false, "Do not verify the server certificate")
+	flag.StringVar(&opts.token, "token", os.Getenv("GOSV_TOKEN"), "API token for the gRPC listener (default $GOSV_TOKEN)")
+	flag.StringVar(&opts.tokenFile, "token-file", "", "File to read the API token from")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
 	}
+	flag.Parse()
 
-	conn, err := grpc.Dial(os.Args[1], grpc.WithInsecure())
+	args := flag.Args()
+	// Старый формат: client <server:port> <command> [args]
+	if len(args) >= 2 && !slices.Contains(commands, args[0]) && slices.Contains(commands, args[1]) {
+		opts.addr, args = args[0], args[1:]
+	}
+	if len(args) == 0 {
+		flag.Usage()
+		os.Exit(exitUsage)
+	}
+	if !slices.Contains(commands, args[0]) {
+		usageError("unknown command %q", args[0])
+	}
+
+	if opts.addr == "" {
+		socket, err := config.ControlSocket(opts.config)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: cannot find the control socket: %v\nUse -c with the daemon's config file or -addr.\n", err)
+			os.Exit(exitUnavailable)
+		}
+		opts.addr = "unix:" + socket
+	}
+
+	out, err := newPrinter(opts.output)
+	if err != nil {
+		usageError("%v", err)
+	}
+
+	conn, err := dial(opts)
 	if err != nil {
-		log.Fatal(err)
+		exit(err)
 	}
 	defer conn.Close()
 
-	client := gosv.NewSupervisorClient(conn)
+	c := &cli{
+		client:  gosv.NewSupervisorClient(conn),
+		timeout: opts.timeout,
+		out:     out,
+	}
+	os.Exit(c.run(args[0], args[1:]))
+}
 
-	switch os.Args[2] {
-	case "status":
-		resp, err := client.GetStatus(context.Background(), &gosv.StatusRequest{})
+func dial(opts options) (*grpc.ClientConn, error) {
+	if path, ok := strings.CutPrefix(opts.addr, "unix:"); ok {
+		// unix:///tmp/gosv.sock и unix:/tmp/gosv.sock равнозначны
+		return api.DialControlSocket(strings.TrimPrefix(path, "//"))
+	}
+
+	creds, err := transportCredentials(opts)
+	if err != nil {
+		usageError("%v", err)
+	}
+	dialOpts := []grpc.DialOption{grpc.WithTransportCredentials(creds)}
+
+	token := opts.token
+	if opts.tokenFile != "" {
+		data, err := os.ReadFile(opts.tokenFile)
 		if err != nil {
-			log.Fatal(err)
+			usageError("%v", err)
 		}
-
-		fmt.Println("Processes status:")
-		for _, proc := range resp.Processes {
-			// Исправляем форматирование - добавляем закрывающую скобку
-			fmt.Printf("- %s: %s (PID: %d, restarts: %d)",
-				proc.Name, proc.Status, proc.Pid, proc.Restarts)
-			if proc.Error != "" {
-				fmt.Printf(", error: %s", proc.Error)
-			}
-			fmt.Println() // Добавляем перевод строки
+		token = strings.TrimSpace(string(data))
+	}
+	if token != "" {
+		// Без TLS токен отправляем только на свою машину
+		requireTLS := !isLoopback(opts.addr)
+		if requireTLS && creds.Info().SecurityProtocol == "insecure" {
+			usageError("sending a token to %s needs -tls", opts.addr)
 		}
+		dialOpts = append(dialOpts, grpc.WithPerRPCCredentials(bearerToken{token: token, requireTLS: requireTLS}))
+	}
+	return grpc.NewClient(opts.addr, dialOpts...)
+}
 
-	case "start":
-		if len(os.Args) < 4 {
-			log.Fatal("Missing process name")
-		}
-		resp, err := client.StartProcess(context.Background(), &gosv.ProcessRequest{Name: os.Args[3]})
+// bearerToken sends the API token with every call.
+type bearerToken struct {
+	token      string
+	requireTLS bool
+}
+
+func (t bearerToken) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + t.token}, nil
+}
+
+func (t bearerToken) RequireTransportSecurity() bool {
+	return t.requireTLS
+}
+
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func transportCredentials(opts options) (credentials.TransportCredentials, error) {
+	if !opts.tls && opts.tlsCA == "" && opts.tlsCert == "" && opts.tlsServerName == "" && !opts.tlsSkipVerify {
+		return insecure.NewCredentials(), nil
+	}
+
+	cfg := &tls.Config{
+		ServerName:         opts.tlsServerName,
+		InsecureSkipVerify: opts.tlsSkipVerify,
+	}
+	if opts.tlsCA != "" {
+		pem, err := os.ReadFile(opts.tlsCA)
 		if err != nil {
-			log.Fatal(err)
+			return nil, err
 		}
-		fmt.Printf("Start response: success=%v, message=%s\n", resp.Success, resp.Message)
-
-	case "stop":
-		if len(os.Args) < 4 {
-			log.Fatal("Missing process name")
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", opts.tlsCA)
 		}
-		resp, err := client.StopProcess(context.Background(), &gosv.ProcessRequest{Name: os.Args[3]})
+		cfg.RootCAs = pool
+	}
+	if opts.tlsCert != "" || opts.tlsKey != "" {
+		cert, err := tls.LoadX509KeyPair(opts.tlsCert, opts.tlsKey)
 		if err != nil {
-			log.Fatal(err)
+			return nil, fmt.Errorf("loading client certificate: %w", err)
 		}
-		fmt.Printf("Stop response: success=%v, message=%s\n", resp.Success, resp.Message)
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return credentials.NewTLS(cfg), nil
+}
+
+// exitCode maps an error onto the exit status.
+func exitCode(err error) int {
+	if errors.Is(err, api.ErrNoDaemon) {
+		return exitUnavailable
+	}
+	switch status.Code(err) {
+	case codes.Unavailable:
+		return exitUnavailable
+	case codes.InvalidArgument:
+		return exitUsage
+	case codes.Unauthenticated, codes.PermissionDenied:
+		return exitDenied
+	}
+	return exitFailed
+}
 
-	default:
-		log.Fatalf("Unknown command: %s", os.Args[2])
+// errorMessage strips the gRPC decoration from server errors.
+func errorMessage(err error) string {
+	if st, ok := status.FromError(err); ok {
+		return st.Message()
+	}
+	return err.Error()
+}
+
+func exit(err error) {
+	fmt.Fprintf(os.Stderr, "Error: %s\n", errorMessage(err))
+	os.Exit(exitCode(err))
+}
+
+func usageError(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, "Error: "+format+"\n", args...)
+	fmt.Fprintln(os.Stderr, "Run with -h for usage.")
+	os.Exit(exitUsage)
+}
+
+// cli runs one command against the daemon.
+type cli struct {
+	client  gosv.SupervisorClient
+	timeout time.Duration
+	out     *printer
+}
+
+func (c *cli) context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), c.timeout)
+}
+
+func (c *cli) run(command string, args []string) int {
+	switch command {
+	case "status":
+		return c.status(args)
+	case "start", "stop", "restart":
+		return c.control(command, args)
+	case "signal":
+		return c.signal(args)
+	case "groups":
+		return c.groups(args)
+	case "reload":
+		return c.reload(args)
+	case "add", "update":
+		return c.configure(command, args)
+	case "remove":
+		return c.remove(args)
+	case "logs":
+		return c.logs(args)
+	case "watch":
+		return c.watch(args)
+	case "audit":
+		return c.audit(args)
 	}
+	return exitUsage
 }
@@ -0,0 +1,346 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/kolkov/gosv/api/gosv"
+	"gopkg.in/yaml.v3"
+)
+
+const timeFormat = "2006/01/02 15:04:05"
+
+// printer renders command results as a human-readable table or as JSON or
+// YAML for scripts.
+type printer struct {
+	format string
+	w      io.Writer
+}
+
+func newPrinter(format string) (*printer, error) {
+	switch format {
+	case "table", "json", "yaml":
+		return &printer{format: format, w: os.Stdout}, nil
+	}
+	return nil, fmt.Errorf("unknown output format %q: want table, json or yaml", format)
+}
+
+// print renders a complete result; table writes the human-readable form.
+func (p *printer) print(v any, table func(w *tabwriter.Writer)) {
+	switch p.format {
+	case "json":
+		enc := json.NewEncoder(p.w)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		enc.Encode(v)
+	case "yaml":
+		yaml.NewEncoder(p.w).Encode(v)
+	default:
+		tw := tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+		table(tw)
+		tw.Flush()
+	}
+}
+
+// item renders one element of a stream such as logs or watch: a JSON
+// object per line, a YAML document, or the given text line.
+func (p *printer) item(v any, line string) {
+	switch p.format {
+	case "json":
+		enc := json.NewEncoder(p.w)
+		enc.SetEscapeHTML(false)
+		enc.Encode(v)
+	case "yaml":
+		fmt.Fprintln(p.w, "---")
+		yaml.NewEncoder(p.w).Encode(v)
+	default:
+		fmt.Fprintln(p.w, line)
+	}
+}
+
+// result renders a Response for the named target and returns the exit
+// status for it.
+func (p *printer) result(name string, resp *gosv.Response) int {
+	if !resp.Success && p.format == "table" {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", resp.Message)
+		return exitFailed
+	}
+	v := resultView{Name: name, Success: resp.Success, Message: resp.Message}
+	for _, ps := range resp.Processes {
+		v.Processes = append(v.Processes, newProcessView(ps))
+	}
+	p.print(v, func(w *tabwriter.Writer) {
+		if name != "" {
+			fmt.Fprintf(w, "%s: %s\n", name, resp.Message)
+		} else {
+			fmt.Fprintln(w, resp.Message)
+		}
+	})
+	if !resp.Success {
+		return exitFailed
+	}
+	return exitOK
+}
+
+type resultView struct {
+	Name    string `json:"name,omitempty" yaml:"name,omitempty"`
+	Success bool   `json:"success" yaml:"success"`
+	Message string `json:"message" yaml:"message"`
+
+	Processes []processView `json:"processes,omitempty" yaml:"processes,omitempty"`
+}
+
+// changes renders the outcome of a reload or runtime configuration change.
+func (p *printer) changes(resp *gosv.ChangeResponse) {
+	// Пустые списки выводим как [], а не null
+	v := changeView{
+		Message:   resp.Message,
+		DryRun:    resp.DryRun,
+		Added:     append([]string{}, resp.GetChanges().GetAdded()...),
+		Updated:   append([]string{}, resp.GetChanges().GetUpdated()...),
+		Removed:   append([]string{}, resp.GetChanges().GetRemoved()...),
+		Unchanged: append([]string{}, resp.GetChanges().GetUnchanged()...),
+	}
+	for _, ps := range resp.Processes {
+		v.Processes = append(v.Processes, newProcessView(ps))
+	}
+
+	p.print(v, func(w *tabwriter.Writer) {
+		fmt.Fprintln(w, v.Message)
+		for _, c := range []struct {
+			label string
+			names []string
+		}{{"added", v.Added}, {"updated", v.Updated}, {"removed", v.Removed}} {
+			for _, name := range c.names {
+				fmt.Fprintf(w, "  %s\t%s\n", c.label, name)
+			}
+		}
+		fmt.Fprintf(w, "  %d unchanged\n", len(v.Unchanged))
+	})
+}
+
+type changeView struct {
+	Message   string   `json:"message" yaml:"message"`
+	DryRun    bool     `json:"dry_run" yaml:"dry_run"`
+	Added     []string `json:"added" yaml:"added"`
+	Updated   []string `json:"updated" yaml:"updated"`
+	Removed   []string `json:"removed" yaml:"removed"`
+	Unchanged []string `json:"unchanged" yaml:"unchanged"`
+
+	Processes []processView `json:"processes,omitempty" yaml:"processes,omitempty"`
+}
+
+type processView struct {
+	Name         string     `json:"name" yaml:"name"`
+	Group        string     `json:"group,omitempty" yaml:"group,omitempty"`
+	Status       string     `json:"status" yaml:"status"`
+	PID          int32      `json:"pid,omitempty" yaml:"pid,omitempty"`
+	Ready        bool       `json:"ready" yaml:"ready"`
+	StartTime    *time.Time `json:"start_time,omitempty" yaml:"start_time,omitempty"`
+	Restarts     int32      `json:"restarts" yaml:"restarts"`
+	MaxRestarts  int32      `json:"max_restarts" yaml:"max_restarts"`
+	StartRetries int32      `json:"start_retries,omitempty" yaml:"start_retries,omitempty"`
+	ExitCode     int32      `json:"exit_code" yaml:"exit_code"`
+	Error        string     `json:"error,omitempty" yaml:"error,omitempty"`
+	Usage        *usageView `json:"usage,omitempty" yaml:"usage,omitempty"`
+}
+
+// usageView is the resource usage of a process and its descendants.
+type usageView struct {
+	CPUPercent float64   `json:"cpu_percent" yaml:"cpu_percent"`
+	CPUSeconds float64   `json:"cpu_seconds" yaml:"cpu_seconds"`
+	RSSBytes   uint64    `json:"rss_bytes" yaml:"rss_bytes"`
+	Threads    int32     `json:"threads" yaml:"threads"`
+	FDs        int32     `json:"fds" yaml:"fds"`
+	ReadBytes  uint64    `json:"read_bytes" yaml:"read_bytes"`
+	WriteBytes uint64    `json:"write_bytes" yaml:"write_bytes"`
+	Processes  int32     `json:"processes" yaml:"processes"`
+	Time       time.Time `json:"time" yaml:"time"`
+}
+
+func newProcessView(ps *gosv.ProcessStatus) processView {
+	v := processView{
+		Name:         ps.Name,
+		Group:        ps.Group,
+		Status:       ps.Status,
+		PID:          ps.Pid,
+		Ready:        ps.Ready,
+		Restarts:     ps.Restarts,
+		MaxRestarts:  ps.MaxRestarts,
+		StartRetries: ps.StartRetries,
+		ExitCode:     ps.ExitCode,
+		Error:        ps.Error,
+	}
+	if ps.StartTime != nil {
+		t := ps.StartTime.AsTime().Local()
+		v.StartTime = &t
+	}
+	if u := ps.Usage; u != nil {
+		v.Usage = &usageView{
+			CPUPercent: u.CpuPercent,
+			CPUSeconds: u.CpuTime.AsDuration().Seconds(),
+			RSSBytes:   u.RssBytes,
+			Threads:    u.Threads,
+			FDs:        u.Fds,
+			ReadBytes:  u.ReadBytes,
+			WriteBytes: u.WriteBytes,
+			Processes:  u.Processes,
+			Time:       u.Time.AsTime().Local(),
+		}
+	}
+	return v
+}
+
+// cpu and memory show the last usage sample, "-" if there is none.
+func (v processView) cpu() string {
+	if v.Usage == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", v.Usage.CPUPercent)
+}
+
+func (v processView) memory() string {
+	if v.Usage == nil {
+		return "-"
+	}
+	return formatBytes(v.Usage.RSSBytes)
+}
+
+func formatBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	value, suffix := float64(n)/unit, "KMGTPE"
+	for value >= unit && len(suffix) > 1 {
+		value, suffix = value/unit, suffix[1:]
+	}
+	return fmt.Sprintf("%.1f%c", value, suffix[0])
+}
+
+// uptime is shown for active processes only.
+func (v processView) uptime() string {
+	if v.StartTime == nil || v.PID == 0 {
+		return "-"
+	}
+	return time.Since(*v.StartTime).Round(time.Second).String()
+}
+
+type groupView struct {
+	Name    string   `json:"name" yaml:"name"`
+	Members []string `json:"members" yaml:"members"`
+	Running int      `json:"running" yaml:"running"`
+	Failed  int      `json:"failed" yaml:"failed"`
+}
+
+type logView struct {
+	Time    time.Time `json:"time" yaml:"time"`
+	Level   string    `json:"level" yaml:"level"`
+	Process string    `json:"process,omitempty" yaml:"process,omitempty"`
+	PID     int32     `json:"pid,omitempty" yaml:"pid,omitempty"`
+	Stream  string    `json:"stream" yaml:"stream"`
+	Message string    `json:"message" yaml:"message"`
+}
+
+func newLogView(rec *gosv.LogRecord) logView {
+	return logView{
+		Time:    rec.Time.AsTime().Local(),
+		Level:   rec.Level,
+		Process: rec.Process,
+		PID:     rec.Pid,
+		Stream:  rec.Stream,
+		Message: rec.Message,
+	}
+}
+
+func formatRecord(rec *gosv.LogRecord) string {
+	var text string
+	switch {
+	case rec.Pid != 0:
+		text = fmt.Sprintf("[%s][%d] %s", rec.Process, rec.Pid, rec.Message)
+	case rec.Process != "":
+		text = fmt.Sprintf("[%s] %s", rec.Process, rec.Message)
+	default:
+		text = rec.Message
+	}
+	return fmt.Sprintf("%s %-5s %-10s %s",
+		rec.Time.AsTime().Local().Format(timeFormat), rec.Level, rec.Stream, text)
+}
+
+type auditView struct {
+	Time   time.Time `json:"time" yaml:"time"`
+	Actor  string    `json:"actor" yaml:"actor"`
+	Source string    `json:"source" yaml:"source"`
+	Action string    `json:"action" yaml:"action"`
+	Target string    `json:"target,omitempty" yaml:"target,omitempty"`
+	Detail string    `json:"detail,omitempty" yaml:"detail,omitempty"`
+	Result string    `json:"result" yaml:"result"`
+	Error  string    `json:"error,omitempty" yaml:"error,omitempty"`
+
+	Processes []string `json:"processes,omitempty" yaml:"processes,omitempty"`
+}
+
+func newAuditView(r *gosv.AuditRecord) auditView {
+	return auditView{
+		Time:   r.Time.AsTime().Local(),
+		Actor:  r.Actor,
+		Source: r.Source,
+		Action: r.Action,
+		Target: r.Target,
+		Detail: r.Detail,
+		Result: r.Result,
+		Error:  r.Error,
+
+		Processes: r.Processes,
+	}
+}
+
+type eventView struct {
+	Time     time.Time `json:"time" yaml:"time"`
+	Process  string    `json:"process" yaml:"process"`
+	Group    string    `json:"group,omitempty" yaml:"group,omitempty"`
+	From     string    `json:"from,omitempty" yaml:"from,omitempty"`
+	To       string    `json:"to" yaml:"to"`
+	PID      int32     `json:"pid,omitempty" yaml:"pid,omitempty"`
+	ExitCode int32     `json:"exit_code,omitempty" yaml:"exit_code,omitempty"`
+	Error    string    `json:"error,omitempty" yaml:"error,omitempty"`
+	Snapshot bool      `json:"snapshot,omitempty" yaml:"snapshot,omitempty"`
+}
+
+func newEventView(ev *gosv.ProcessEvent) eventView {
+	return eventView{
+		Time:     ev.Time.AsTime().Local(),
+		Process:  ev.Process,
+		Group:    ev.Group,
+		From:     ev.From,
+		To:       ev.To,
+		PID:      ev.Pid,
+		ExitCode: ev.ExitCode,
+		Error:    ev.Error,
+		Snapshot: ev.Snapshot,
+	}
+}
+
+func formatEvent(ev *gosv.ProcessEvent) string {
+	when := ev.Time.AsTime().Local().Format(timeFormat)
+	if ev.Snapshot {
+		return fmt.Sprintf("%s %s: %s", when, ev.Process, ev.To)
+	}
+
+	s := fmt.Sprintf("%s %s: %s -> %s", when, ev.Process, ev.From, ev.To)
+	if ev.Pid != 0 {
+		s += fmt.Sprintf(" (PID: %d)", ev.Pid)
+	}
+	switch ev.To {
+	case "exited", "failed", "backoff", "fatal":
+		s += fmt.Sprintf(", exit code %d", ev.ExitCode)
+		if ev.Error != "" {
+			s += ", " + ev.Error
+		}
+	}
+	return s
+}
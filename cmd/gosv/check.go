@@ -0,0 +1,48 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/kolkov/gosv/internal/config"
+)
+
+// runCheck validates config files without starting anything. It prints
+// every problem found and returns the exit status: 0 if all files are
+// valid, 1 otherwise.
+func runCheck(args []string) int {
+	fs := flag.NewFlagSet("check", flag.ExitOnError)
+	cfgPath := fs.String("c", "gsv.yaml", "Path to configuration file")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: gosv check [-c file] [file...]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	paths := fs.Args()
+	if len(paths) == 0 {
+		paths = []string{*cfgPath}
+	}
+
+	status := 0
+	for _, path := range paths {
+		cfg, err := config.Load(path)
+		var ve *config.ValidationError
+		switch {
+		case errors.As(err, &ve):
+			for _, p := range ve.Problems {
+				fmt.Fprintln(os.Stderr, ve.Format(p))
+			}
+			fmt.Fprintf(os.Stderr, "%s: %d problem(s) found\n", path, len(ve.Problems))
+			status = 1
+		case err != nil:
+			fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+			status = 1
+		default:
+			fmt.Printf("%s: OK, %d programs\n", path, len(cfg.Processes))
+		}
+	}
+	return status
+}
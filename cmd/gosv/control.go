@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/kolkov/gosv/api/gosv"
+	"github.com/kolkov/gosv/internal/api"
+	"github.com/kolkov/gosv/internal/supervisor"
+	"google.golang.org/grpc/status"
+)
+
+// controlTimeout bounds a control command; starting may wait for
+// start_secs and dependencies.
+const controlTimeout = 2 * time.Minute
+
+var actionDone = map[string]string{
+	"start":   "started",
+	"stop":    "stopped",
+	"restart": "restarted",
+}
+
+// runControlCommand performs -status, -reload, -start, -stop or -restart
+// on the daemon behind the control socket and prints the resulting
+// status.
+func runControlCommand(socket, action, name string) {
+	conn, err := api.DialControlSocket(socket)
+	if err != nil {
+		log.Fatalf("[ERROR] %v", err)
+	}
+	defer conn.Close()
+
+	client := gosv.NewSupervisorClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), controlTimeout)
+	defer cancel()
+
+	var resp *gosv.Response
+	req := &gosv.ProcessRequest{Name: name}
+	switch action {
+	case "reload":
+		var changes *gosv.ChangeResponse
+		if changes, err = client.ReloadConfig(ctx, &gosv.ReloadRequest{}); err == nil {
+			cs := changes.Changes
+			fmt.Printf("%s: %d added, %d updated, %d removed, %d unchanged\n", changes.Message,
+				len(cs.Added), len(cs.Updated), len(cs.Removed), len(cs.Unchanged))
+		}
+	case "start":
+		resp, err = client.StartProcess(ctx, req)
+	case "stop":
+		resp, err = client.StopProcess(ctx, req)
+	case "restart":
+		resp, err = client.RestartProcess(ctx, req)
+	}
+	if err != nil {
+		log.Fatalf("[ERROR] %s failed: %s", action, status.Convert(err).Message())
+	}
+	if resp != nil {
+		if !resp.Success {
+			log.Fatalf("[ERROR] %s failed: %s", action, resp.Message)
+		}
+		if name != "" {
+			fmt.Printf("Process '%s' %s\n", name, actionDone[action])
+		} else {
+			fmt.Println(resp.Message)
+		}
+	}
+
+	st, err := client.GetStatus(ctx, &gosv.StatusRequest{})
+	if err != nil {
+		log.Fatalf("[ERROR] status failed: %v", err)
+	}
+	statuses := make(map[string]*supervisor.ProcessInfo, len(st.Processes))
+	for _, ps := range st.Processes {
+		statuses[ps.Name] = api.ProcessInfoFromProto(ps)
+	}
+	supervisor.PrintStatuses(statuses)
+}
@@ -6,22 +6,44 @@ import (
 	"github.com/kolkov/gosv/internal/api"
 	"github.com/kolkov/gosv/internal/service"
 	"log"
+	"net"
 	"os"
 	"os/signal"
+	"runtime"
 	"strings"
 	"syscall"
 	"time"
 
+	"github.com/kolkov/gosv/internal/audit"
 	"github.com/kolkov/gosv/internal/config"
+	"github.com/kolkov/gosv/internal/logging"
+	"github.com/kolkov/gosv/internal/metrics"
 	"github.com/kolkov/gosv/internal/process"
 	"github.com/kolkov/gosv/internal/supervisor"
 )
 
 var grpcPort string
 
-func ensureConfigExists(path string) error {
-	if _, err := os.Stat(path); os.IsNotExist(err) {
-		defaultConfig := `processes:
+// defaultConfig is written when the config file does not exist.
+func defaultConfig() string {
+	if runtime.GOOS != "windows" {
+		return `processes:
+  - name: "example-process"
+    command: "sh"
+    args: ["-c", "echo Hello World && sleep 30"]
+    autostart: true
+    autorestart: "always"
+    stop_signal: "SIGKILL"
+    stop_wait: 5s
+
+  - name: "ping-test"
+    command: "ping"
+    args: ["-c", "30", "localhost"]
+    autostart: true
+    autorestart: "always"
+`
+	}
+	return `processes:
   - name: "example-process"
     command: "cmd.exe"
     args: ["/c", "echo Hello World && timeout /t 30 /nobreak"]
@@ -36,7 +58,11 @@ func ensureConfigExists(path string) error {
     autostart: true
     autorestart: "always"
 `
-		if err := os.WriteFile(path, []byte(defaultConfig), 0644); err != nil {
+}
+
+func ensureConfigExists(path string) error {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		if err := os.WriteFile(path, []byte(defaultConfig()), 0644); err != nil {
 			return fmt.Errorf("failed to create default config: %w", err)
 		}
 		log.Printf("[INFO] Created default config at %s", path)
@@ -45,22 +71,33 @@ func ensureConfigExists(path string) error {
 }
 
 func main() {
+	// gosv check [-c file | file...] проверяет конфигурацию и выходит
+	if len(os.Args) > 1 && (os.Args[1] == "check" || os.Args[1] == "validate") {
+		os.Exit(runCheck(os.Args[2:]))
+	}
+
 	// Глобальные флаги
 	cfgPath := flag.String("c", "gsv.yaml", "Path to configuration file")
 	tuiMode := flag.Bool("tui", false, "Enable terminal UI mode")
 	debugMode := flag.Bool("debug", false, "Enable debug logging")
-	// Добавляем флаг для gRPC порта
-	grpcPort := flag.String("grpc-port", "", "gRPC server port (empty to disable)")
-	flag.Parse()
+	logFormat := flag.String("log-format", "text", "Debug log format: text or json")
+	// gRPC для удалённых клиентов; по умолчанию выключен
+	grpcAddr := flag.String("grpc-addr", "", "gRPC listen address such as 127.0.0.1:50051 (overrides grpc.address)")
+	grpcPort := flag.String("grpc-port", "", "gRPC server port on all interfaces; prefer -grpc-addr")
+	tlsCert := flag.String("tls-cert", "", "gRPC server certificate (overrides grpc.tls.cert)")
+	tlsKey := flag.String("tls-key", "", "gRPC server certificate key (overrides grpc.tls.key)")
+	tlsClientCA := flag.String("tls-client-ca", "", "CA that gRPC client certificates must be signed by, enables mutual TLS")
+	metricsAddr := flag.String("metrics-addr", "", "Serve Prometheus metrics on this address, e.g. 127.0.0.1:9102 (overrides metrics.address)")
 
 	// Флаги управления процессами
-	startProc := flag.String("start", "", "Start specific process")
-	stopProc := flag.String("stop", "", "Stop specific process")
-	restartProc := flag.String("restart", "", "Restart specific process")
+	startProc := flag.String("start", "", "Start specific process or group (name:*)")
+	stopProc := flag.String("stop", "", "Stop specific process or group (name:*)")
+	restartProc := flag.String("restart", "", "Restart specific process or group (name:*)")
 	runProc := flag.String("run", "", "Run process in foreground mode")
 	listProcs := flag.Bool("list", false, "List all configured processes")
 	status := flag.Bool("status", false, "Show current status")
 	reload := flag.Bool("reload", false, "Reload configuration")
+	socketPath := flag.String("socket", "", "Control socket path (overrides control.socket)")
 
 	flag.Parse()
 
@@ -72,64 +109,173 @@ func main() {
 	// Загрузка конфигурации
 	cfg, err := config.Load(*cfgPath)
 	if err != nil {
-		log.Fatalf("[ERROR] Config load failed: %v", err)
+		log.Fatalf("[ERROR] Config load failed:\n%v", err)
 	}
 
-	// Инициализация супервизора
-	sv := supervisor.New(cfg)
-
-	// Устанавливаем логгер для отладки
-	if *debugMode {
-		sv.SetLogger(func(log string) {
-			fmt.Println(log)
-		})
+	// Флаги командной строки важнее файла, в том числе после перезагрузки
+	overrides := func(cfg *config.Config) error {
+		if *socketPath != "" {
+			cfg.Control.Socket = *socketPath
+		}
+		if *metricsAddr != "" {
+			cfg.Metrics.Address = *metricsAddr
+		}
+		return applyGRPCFlags(&cfg.GRPC, *grpcAddr, *grpcPort, *tlsCert, *tlsKey, *tlsClientCA)
+	}
+	if err := overrides(cfg); err != nil {
+		log.Fatalf("[ERROR] %v", err)
 	}
 
-	// Обработка команд управления процессами
+	// Команды управления обращаются к работающему демону через сокет
 	switch {
 	case *listProcs:
 		listAllProcesses(cfg)
 		return
 
 	case *status:
-		sv.PrintStatus()
+		runControlCommand(cfg.Control.Socket, "status", "")
 		return
 
 	case *reload:
-		handleReload(sv, cfgPath)
+		runControlCommand(cfg.Control.Socket, "reload", "")
 		return
 
 	case *startProc != "":
-		if err := sv.StartProcess(*startProc); err != nil {
-			log.Fatalf("[ERROR] Failed to start process: %v", err)
-		}
-		fmt.Printf("Process '%s' started\n", *startProc)
-		sv.PrintStatus()
+		runControlCommand(cfg.Control.Socket, "start", *startProc)
 		return
 
 	case *stopProc != "":
-		if err := sv.StopProcess(*stopProc); err != nil {
-			log.Fatalf("[ERROR] Failed to stop process: %v", err)
-		}
-		fmt.Printf("Process '%s' stopped\n", *stopProc)
-		sv.PrintStatus()
+		runControlCommand(cfg.Control.Socket, "stop", *stopProc)
 		return
 
 	case *restartProc != "":
-		if err := sv.RestartProcess(*restartProc); err != nil {
-			log.Fatalf("[ERROR] Failed to restart process: %v", err)
-		}
-		fmt.Printf("Process '%s' restarted\n", *restartProc)
-		sv.PrintStatus()
+		runControlCommand(cfg.Control.Socket, "restart", *restartProc)
 		return
+	}
+
+	// Инициализация супервизора
+	sv := supervisor.New(cfg)
+	sv.SetOverrides(overrides)
+
+	// Устанавливаем логгер для отладки; в foreground-режиме свой вывод
+	if *debugMode && *runProc == "" {
+		if *logFormat == "json" {
+			sv.AddSink(logging.NewJSONLines(os.Stdout))
+		} else {
+			sv.AddSink(logging.NewConsole(os.Stdout))
+		}
+	}
 
-	case *runProc != "":
+	if *runProc != "" {
 		runProcessForeground(sv, *runProc)
 		return
 	}
 
 	// Стандартный режим работы
-	runSupervisor(sv, tuiMode, cfgPath, *grpcPort)
+	runSupervisor(sv, cfg, tuiMode)
+}
+
+// applyGRPCFlags lets the command line override the grpc section.
+func applyGRPCFlags(g *config.GRPCConfig, addr, port, cert, key, clientCA string) error {
+	switch {
+	case addr != "":
+		g.Address = addr
+	case port != "":
+		g.Address = ":" + port
+	}
+
+	if cert == "" && key == "" && clientCA == "" {
+		return nil
+	}
+	if g.TLS == nil {
+		g.TLS = &config.TLSConfig{}
+	}
+	if cert != "" {
+		g.TLS.Cert = cert
+	}
+	if key != "" {
+		g.TLS.Key = key
+	}
+	if clientCA != "" {
+		g.TLS.ClientCA = clientCA
+	}
+	if g.TLS.Cert == "" || g.TLS.Key == "" {
+		return fmt.Errorf("TLS needs both -tls-cert and -tls-key")
+	}
+	return nil
+}
+
+// serveGRPC starts the TCP listener of the API if an address is
+// configured. reload re-reads the certificates and token files.
+func serveGRPC(sv *supervisor.Supervisor, g config.GRPCConfig, auth config.AuthConfig, m *metrics.Metrics) (reload, stop func()) {
+	if g.Address == "" {
+		if len(auth.Tokens) > 0 {
+			log.Println("[WARN] auth tokens are configured but the gRPC listener is disabled")
+		}
+		return func() {}, func() {}
+	}
+
+	var certs *api.CertStore
+	if g.TLS != nil {
+		var err error
+		if certs, err = api.NewCertStore(*g.TLS); err != nil {
+			log.Fatalf("[ERROR] gRPC TLS: %v", err)
+		}
+	} else if !isLoopback(g.Address) {
+		log.Printf("[WARN] gRPC API on %s is not encrypted; configure grpc.tls", g.Address)
+	}
+
+	authz, err := api.NewAuthorizer(service.AsService(sv), auth)
+	if err != nil {
+		log.Fatalf("[ERROR] auth: %v", err)
+	}
+	if authz == nil && !isLoopback(g.Address) {
+		log.Printf("[WARN] gRPC API on %s accepts anyone who can connect; configure auth tokens", g.Address)
+	}
+
+	stop, err = api.ServeGRPC(service.AsService(sv), g.Address, certs, authz, m)
+	if err != nil {
+		log.Fatalf("[ERROR] gRPC server: %v", err)
+	}
+	switch {
+	case certs != nil && certs.MutualTLS():
+		log.Printf("[INFO] gRPC server listening on %s (mutual TLS)", g.Address)
+	case certs != nil:
+		log.Printf("[INFO] gRPC server listening on %s (TLS)", g.Address)
+	default:
+		log.Printf("[INFO] gRPC server listening on %s", g.Address)
+	}
+
+	// Обновлённые сертификаты и токены подхватываем без перезапуска сервера
+	reload = func() {
+		if certs != nil {
+			if err := certs.Reload(); err != nil {
+				log.Printf("[ERROR] TLS certificate reload failed, keeping the old ones: %v", err)
+			} else {
+				log.Println("[INFO] TLS certificates reloaded")
+			}
+		}
+		if authz != nil {
+			if err := authz.Reload(); err != nil {
+				log.Printf("[ERROR] Auth token reload failed, keeping the old ones: %v", err)
+			} else {
+				log.Println("[INFO] Auth tokens reloaded")
+			}
+		}
+	}
+	return reload, stop
+}
+
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
 }
 
 func listAllProcesses(cfg *config.Config) {
@@ -138,21 +284,31 @@ func listAllProcesses(cfg *config.Config) {
 		fmt.Printf("%d. %s\n", i+1, p.Name)
 		fmt.Printf("   Command: %s %s\n", p.Command, strings.Join(p.Args, " "))
 		fmt.Printf("   Autostart: %v, Autorestart: %s\n", p.Autostart, p.Autorestart)
+		if p.NumProcs > 1 {
+			fmt.Printf("   Instances: %d (from %d)\n", p.NumProcs, p.NumProcsStart)
+		}
+		if p.StdoutLogfile != "" || p.StderrLogfile != "" {
+			stderrLog := p.StderrLogfile
+			if p.RedirectStderr {
+				stderrLog = "(stdout)"
+			}
+			fmt.Printf("   Logs: stdout=%s, stderr=%s (rotate at %v, keep %d)\n",
+				p.StdoutLogfile, stderrLog, p.LogRotate.MaxSize, p.LogRotate.Backups())
+		}
+		if len(p.DependsOn) > 0 {
+			deps := make([]string, 0, len(p.DependsOn))
+			for _, d := range p.DependsOn {
+				deps = append(deps, fmt.Sprintf("%s (%s)", d.Name, d.Condition))
+			}
+			fmt.Printf("   Depends on: %s\n", strings.Join(deps, ", "))
+		}
+		if el := p.EventListener; el != nil {
+			fmt.Printf("   Event listener: %s (buffer %d)\n", strings.Join(el.Events, ", "), el.BufferSize)
+		}
 		fmt.Println()
 	}
 }
 
-func handleReload(sv *supervisor.Supervisor, cfgPath *string) {
-	fmt.Println("[INFO] Reloading configuration...")
-	newCfg, err := config.Load(*cfgPath)
-	if err != nil {
-		log.Fatalf("[ERROR] Config reload failed: %v", err)
-	}
-	sv.ReloadConfig(newCfg)
-	fmt.Println("[INFO] Configuration reloaded successfully")
-	sv.PrintStatus()
-}
-
 func runProcessForeground(sv *supervisor.Supervisor, procName string) {
 	fmt.Printf("Running process '%s' in foreground...\n", procName)
 
@@ -160,14 +316,14 @@ func runProcessForeground(sv *supervisor.Supervisor, procName string) {
 	done := make(chan struct{})
 
 	// Специальный логгер для foreground режима
-	sv.SetLogger(func(log string) {
-		if strings.Contains(log, "["+procName+"]") {
-			fmt.Println(log)
-		}
-	})
+	sv.AddSink(logging.Filtered(logging.NewConsole(os.Stdout), logging.Filter{
+		Processes: []string{procName},
+	}))
 
 	// Запускаем процесс
-	if err := sv.StartProcess(procName); err != nil {
+	err := sv.StartProcess(procName)
+	sv.Audit(audit.NewRecord(audit.LocalActor(), "cli", "start", procName, err))
+	if err != nil {
 		log.Fatalf("[ERROR] Failed to start process: %v", err)
 	}
 
@@ -179,7 +335,8 @@ func runProcessForeground(sv *supervisor.Supervisor, procName string) {
 	go func() {
 		<-sigCh
 		fmt.Printf("\nStopping process '%s'...\n", procName)
-		sv.StopProcess(procName)
+		err := sv.StopProcess(procName)
+		sv.Audit(audit.NewRecord(audit.LocalActor(), "cli", "stop", procName, err))
 		close(done)
 	}()
 
@@ -194,7 +351,7 @@ func runProcessForeground(sv *supervisor.Supervisor, procName string) {
 			return
 		case <-ticker.C:
 			status := sv.GetProcessStatus(procName)
-			if status == process.Stopped || status == process.Failed {
+			if status == process.Stopped || status == process.Failed || status == process.Exited || status == process.Fatal {
 				fmt.Println("Process completed")
 				return
 			}
@@ -202,21 +359,43 @@ func runProcessForeground(sv *supervisor.Supervisor, procName string) {
 	}
 }
 
-func runSupervisor(sv *supervisor.Supervisor, tuiMode *bool, cfgPath *string, grpcPort string) {
+func runSupervisor(sv *supervisor.Supervisor, cfg *config.Config, tuiMode *bool) {
+	// Запросы API считаем, только если метрики кто-то читает
+	var m *metrics.Metrics
+	if cfg.Metrics.Address != "" {
+		m = metrics.New(sv)
+	}
+
+	control := cfg.Control
+	// Сокет открываем до запуска процессов: второй демон не должен
+	// поднять их повторно
+	stopControl, err := api.ServeControlSocket(service.AsService(sv), control.Socket, control.FileMode(), m)
+	if err != nil {
+		log.Fatalf("[ERROR] Control socket: %v", err)
+	}
+	defer stopControl()
+	log.Printf("[INFO] Control socket listening on %s", control.Socket)
+
+	// Метрики поднимаем до запуска процессов, чтобы был виден и он
+	if m != nil {
+		stopMetrics, err := metrics.Serve(m, cfg.Metrics.Address)
+		if err != nil {
+			log.Fatalf("[ERROR] Metrics server: %v", err)
+		}
+		defer stopMetrics()
+		log.Printf("[INFO] Metrics served on http://%s/metrics", cfg.Metrics.Address)
+	}
+
 	// Запуск всех процессов с autostart
+	// Неудачный запуск отдельного процесса не должен останавливать супервизор
 	if err := sv.StartAll(); err != nil {
-		log.Fatalf("[ERROR] Startup failed: %v", err)
+		log.Printf("[ERROR] Startup incomplete: %v", err)
 	}
 	log.Println("[INFO] Supervisor started")
 
 	// Запуск gRPC сервера
-	if grpcPort != "" {
-		go func() {
-			log.Printf("Starting gRPC server on :%s", grpcPort)
-			// Передаем интерфейс service.SupervisorService
-			api.StartGRPCServer(service.AsService(sv), grpcPort)
-		}()
-	}
+	reloadGRPC, stopGRPC := serveGRPC(sv, cfg.GRPC, cfg.Auth, m)
+	defer stopGRPC()
 
 	// Краткая задержка для запуска процессов
 	time.Sleep(500 * time.Millisecond)
@@ -244,13 +423,16 @@ func runSupervisor(sv *supervisor.Supervisor, tuiMode *bool, cfgPath *string, gr
 				switch sig {
 				case syscall.SIGHUP:
 					log.Println("[INFO] Reloading config...")
-					if newCfg, err := config.Load(*cfgPath); err == nil {
-						sv.ReloadConfig(newCfg)
-						log.Println("[INFO] Config reloaded successfully")
+					changes, err := sv.Reload(false)
+					if err == nil {
+						log.Printf("[INFO] Config reloaded: %s", changes)
 						sv.PrintStatus()
 					} else {
 						log.Printf("[ERROR] Config reload failed: %v", err)
 					}
+					// Кто прислал сигнал, узнать нельзя
+					sv.Audit(audit.NewRecord("signal:SIGHUP", "signal", "reload", "", err))
+					reloadGRPC()
 				default:
 					log.Printf("[INFO] Received %s, shutting down...", sig)
 					sv.StopAll()
@@ -0,0 +1,217 @@
+package api
+
+import (
+	"context"
+	"crypto/x509"
+	"net"
+	"slices"
+
+	"github.com/kolkov/gosv/api/gosv"
+	"github.com/kolkov/gosv/internal/audit"
+	"github.com/kolkov/gosv/internal/config"
+	"github.com/kolkov/gosv/internal/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// auditActions names the calls that change something, as they appear in
+// the audit log. Other calls are not recorded.
+var auditActions = map[string]string{
+	gosv.Supervisor_StartProcess_FullMethodName:   "start",
+	gosv.Supervisor_StopProcess_FullMethodName:    "stop",
+	gosv.Supervisor_RestartProcess_FullMethodName: "restart",
+	gosv.Supervisor_SignalProcess_FullMethodName:  "signal",
+	gosv.Supervisor_ReloadConfig_FullMethodName:   "reload",
+	gosv.Supervisor_AddProcess_FullMethodName:     "add",
+	gosv.Supervisor_UpdateProcess_FullMethodName:  "update",
+	gosv.Supervisor_RemoveProcess_FullMethodName:  "remove",
+}
+
+// auditor records the calls in auditActions. It runs before the
+// Authorizer, so that rejected calls are recorded as well.
+type auditor struct {
+	sv     service.SupervisorService
+	source string      // grpc или socket
+	authz  *Authorizer // nil без токенов
+}
+
+func (a *auditor) unary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	action, ok := auditActions[info.FullMethod]
+	if !ok {
+		return handler(ctx, req)
+	}
+	// Пробный прогон ничего не меняет
+	if r, ok := req.(interface{ GetDryRun() bool }); ok && r.GetDryRun() {
+		return handler(ctx, req)
+	}
+
+	rec := audit.Record{
+		Actor:  a.actor(ctx),
+		Source: a.source,
+		Action: action,
+		Result: audit.ResultOK,
+	}
+	rec.Target, rec.Detail = auditTarget(req)
+	// Состав группы запоминаем до вызова: после remove его уже не узнать
+	if rec.Target != "" {
+		if names, err := a.sv.ProcessNames(rec.Target); err == nil && !slices.Equal(names, []string{rec.Target}) {
+			rec.Processes = names
+		}
+	}
+
+	resp, err := handler(ctx, req)
+	switch status.Code(err) {
+	case codes.OK:
+	case codes.Unauthenticated, codes.PermissionDenied:
+		rec.Result, rec.Error = audit.ResultDenied, status.Convert(err).Message()
+	default:
+		rec.Result, rec.Error = audit.ResultFailed, status.Convert(err).Message()
+	}
+	a.sv.Audit(rec)
+	return resp, err
+}
+
+// actor identifies the caller by token, client certificate or socket peer,
+// falling back to the client address.
+func (a *auditor) actor(ctx context.Context) string {
+	var from string
+	if p, ok := peer.FromContext(ctx); ok {
+		switch info := p.AuthInfo.(type) {
+		case peerInfo:
+			if info.actor != "" {
+				return info.actor
+			}
+			return "local"
+		case credentials.TLSInfo:
+			if cert := verifiedClient(info); cert != nil {
+				from = "cert:" + cert.Subject.CommonName
+			}
+		}
+		if from == "" && p.Addr != nil {
+			from = p.Addr.String()
+			if host, _, err := net.SplitHostPort(from); err == nil {
+				from = host
+			}
+		}
+	}
+
+	if a.authz != nil {
+		if t, err := a.authz.lookup(ctx); err == nil {
+			if from != "" {
+				return "token:" + t.Name + " from " + from
+			}
+			return "token:" + t.Name
+		}
+	}
+	if from == "" {
+		return "unknown"
+	}
+	return from
+}
+
+func verifiedClient(info credentials.TLSInfo) *x509.Certificate {
+	if chains := info.State.VerifiedChains; len(chains) > 0 && len(chains[0]) > 0 {
+		return chains[0][0]
+	}
+	return nil
+}
+
+// auditTarget returns what a call acts on and its arguments worth
+// recording.
+func auditTarget(req any) (target, detail string) {
+	switch r := req.(type) {
+	case *gosv.ProcessRequest:
+		target = targetName(r.Name, r.Group)
+		if r.Force {
+			detail = "force"
+		}
+	case *gosv.SignalRequest:
+		target, detail = targetName(r.Name, r.Group), r.Signal
+	case *gosv.RemoveProcessRequest:
+		target = r.Name
+	case *gosv.ProcessConfigRequest:
+		if pc, err := config.ParseProcess([]byte(r.Config)); err == nil {
+			target = pc.Name
+		}
+	}
+	return target, detail
+}
+
+// targetName writes a group the way the CLI accepts it.
+func targetName(name, group string) string {
+	if group != "" {
+		return group + ":*"
+	}
+	return name
+}
+
+func (s *Server) GetAuditLog(ctx context.Context, req *gosv.AuditRequest) (*gosv.AuditResponse, error) {
+	filter := audit.Filter{
+		Actor:      req.Actor,
+		Action:     req.Action,
+		Target:     req.Name,
+		FailedOnly: req.FailedOnly,
+	}
+	if req.Since != nil {
+		filter.Since = req.Since.AsTime()
+	}
+	if req.Until != nil {
+		filter.Until = req.Until.AsTime()
+	}
+
+	records, err := s.sv.AuditLog(filter, int(req.Limit))
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	resp := &gosv.AuditResponse{Records: make([]*gosv.AuditRecord, 0, len(records))}
+	for _, r := range records {
+		resp.Records = append(resp.Records, &gosv.AuditRecord{
+			Time:      timestamppb.New(r.Time),
+			Actor:     r.Actor,
+			Source:    r.Source,
+			Action:    r.Action,
+			Target:    r.Target,
+			Processes: r.Processes,
+			Detail:    r.Detail,
+			Result:    r.Result,
+			Error:     r.Error,
+		})
+	}
+	return resp, nil
+}
+
+// peerCredentials is the transport of the control socket. It adds no
+// security, the socket permissions do that, but remembers which local
+// user connected.
+type peerCredentials struct {
+	credentials.TransportCredentials
+}
+
+func newPeerCredentials() credentials.TransportCredentials {
+	return peerCredentials{insecure.NewCredentials()}
+}
+
+func (c peerCredentials) ServerHandshake(conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return conn, peerInfo{
+		CommonAuthInfo: credentials.CommonAuthInfo{SecurityLevel: credentials.NoSecurity},
+		actor:          peerActor(conn),
+	}, nil
+}
+
+func (c peerCredentials) Clone() credentials.TransportCredentials {
+	return peerCredentials{c.TransportCredentials.Clone()}
+}
+
+type peerInfo struct {
+	credentials.CommonAuthInfo
+	actor string // пусто, если ОС не сообщает, кто подключился
+}
+
+func (peerInfo) AuthType() string {
+	return "peercred"
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kolkov/gosv/api/gosv"
+	"github.com/kolkov/gosv/internal/audit"
+	"github.com/kolkov/gosv/internal/config"
+	"github.com/kolkov/gosv/internal/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// auditSupervisor writes audit records to a real audit file.
+type auditSupervisor struct {
+	service.SupervisorService
+	log *audit.Log
+}
+
+func (s auditSupervisor) ProcessNames(name string) ([]string, error) {
+	if name == "worker" {
+		return []string{"worker:00", "worker:01"}, nil
+	}
+	return []string{name}, nil
+}
+
+func (s auditSupervisor) Audit(r audit.Record) {
+	s.log.Write(r)
+}
+
+// The auditor runs before the Authorizer, so a denied call is recorded
+// next to an allowed one.
+func TestAuditorRecordsAllowedAndDenied(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	log, err := audit.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer log.Close()
+	sv := auditSupervisor{log: log}
+
+	authz, err := NewAuthorizer(sv, config.AuthConfig{Tokens: []config.TokenConfig{
+		{Name: "viewer", Token: "v-secret", Role: config.RoleViewer},
+		{Name: "operator", Token: "o-secret", Role: config.RoleOperator},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := &auditor{sv: sv, source: "grpc", authz: authz}
+	call := func(secret string, method string, req any) error {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+secret))
+		info := &grpc.UnaryServerInfo{FullMethod: method}
+		_, err := a.unary(ctx, req, info, func(ctx context.Context, req any) (any, error) {
+			return authz.unary(ctx, req, info, func(ctx context.Context, req any) (any, error) {
+				return nil, nil
+			})
+		})
+		return err
+	}
+
+	if err := call("o-secret", gosv.Supervisor_StartProcess_FullMethodName, &gosv.ProcessRequest{Name: "worker"}); err != nil {
+		t.Fatalf("operator start: %v", err)
+	}
+	err = call("v-secret", gosv.Supervisor_SignalProcess_FullMethodName, &gosv.SignalRequest{Name: "web", Signal: "HUP"})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("viewer signal: %v, want %v", err, codes.PermissionDenied)
+	}
+	// Чтение ничего не меняет и не записывается
+	if err := call("v-secret", gosv.Supervisor_GetStatus_FullMethodName, &gosv.StatusRequest{}); err != nil {
+		t.Fatalf("viewer status: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("audit log has %d lines, want 2:\n%s", len(lines), data)
+	}
+	want := []audit.Record{
+		{Actor: "token:operator", Source: "grpc", Action: "start", Target: "worker",
+			Processes: []string{"worker:00", "worker:01"}, Result: audit.ResultOK},
+		{Actor: "token:viewer", Source: "grpc", Action: "signal", Target: "web", Detail: "HUP",
+			Result: audit.ResultDenied, Error: "token viewer has role viewer, /gosv.Supervisor/SignalProcess needs operator"},
+	}
+	for i, line := range lines {
+		var got audit.Record
+		if err := json.Unmarshal([]byte(line), &got); err != nil {
+			t.Fatalf("line %d: %v", i+1, err)
+		}
+		if got.Time.IsZero() {
+			t.Errorf("line %d has no time", i+1)
+		}
+		got.Time = want[i].Time
+		gotJSON, _ := json.Marshal(got)
+		wantJSON, _ := json.Marshal(want[i])
+		if string(gotJSON) != string(wantJSON) {
+			t.Errorf("line %d is\n%s\nwant\n%s", i+1, gotJSON, wantJSON)
+		}
+	}
+}
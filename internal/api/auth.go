@@ -0,0 +1,332 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/kolkov/gosv/api/gosv"
+	"github.com/kolkov/gosv/internal/config"
+	"github.com/kolkov/gosv/internal/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// methodRoles is the role each call needs. Calls missing here, other than
+// reflection, need admin.
+var methodRoles = map[string]config.Role{
+	gosv.Supervisor_GetStatus_FullMethodName:   config.RoleViewer,
+	gosv.Supervisor_GetLogs_FullMethodName:     config.RoleViewer,
+	gosv.Supervisor_StreamLogs_FullMethodName:  config.RoleViewer,
+	gosv.Supervisor_WatchEvents_FullMethodName: config.RoleViewer,
+
+	gosv.Supervisor_StartProcess_FullMethodName:   config.RoleOperator,
+	gosv.Supervisor_StopProcess_FullMethodName:    config.RoleOperator,
+	gosv.Supervisor_RestartProcess_FullMethodName: config.RoleOperator,
+	gosv.Supervisor_SignalProcess_FullMethodName:  config.RoleOperator,
+	gosv.Supervisor_ReloadConfig_FullMethodName:   config.RoleOperator,
+	gosv.Supervisor_GetAuditLog_FullMethodName:    config.RoleOperator,
+
+	gosv.Supervisor_AddProcess_FullMethodName:    config.RoleAdmin,
+	gosv.Supervisor_UpdateProcess_FullMethodName: config.RoleAdmin,
+	gosv.Supervisor_RemoveProcess_FullMethodName: config.RoleAdmin,
+}
+
+func requiredRole(method string) config.Role {
+	if role, ok := methodRoles[method]; ok {
+		return role
+	}
+	// grpcurl только читает описание сервиса
+	if strings.HasPrefix(method, "/grpc.reflection.") {
+		return config.RoleViewer
+	}
+	return config.RoleAdmin
+}
+
+// Authorizer checks the bearer token of every call on the gRPC listener
+// against the auth section: the role of the token must allow the call,
+// and a token limited to some processes may only touch those.
+type Authorizer struct {
+	sv    service.SupervisorService
+	files []config.TokenConfig
+
+	mu     sync.RWMutex
+	tokens map[[sha256.Size]byte]config.TokenConfig
+}
+
+// NewAuthorizer reads the tokens of cfg. It returns nil if cfg defines
+// none, which leaves the API open.
+func NewAuthorizer(sv service.SupervisorService, cfg config.AuthConfig) (*Authorizer, error) {
+	if len(cfg.Tokens) == 0 {
+		return nil, nil
+	}
+	a := &Authorizer{sv: sv, files: cfg.Tokens}
+	if err := a.Reload(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Reload re-reads the token files. On error the previous tokens stay in
+// use.
+func (a *Authorizer) Reload() error {
+	tokens := make(map[[sha256.Size]byte]config.TokenConfig, len(a.files))
+	for _, t := range a.files {
+		secret := t.Token
+		if t.TokenFile != "" {
+			data, err := os.ReadFile(t.TokenFile)
+			if err != nil {
+				return fmt.Errorf("token %s: %w", t.Name, err)
+			}
+			secret = strings.TrimSpace(string(data))
+		}
+		if secret == "" {
+			return fmt.Errorf("token %s is empty", t.Name)
+		}
+		// Храним только хеши: поиск по ним не выдаёт секрет по времени сравнения
+		sum := sha256.Sum256([]byte(secret))
+		if other, ok := tokens[sum]; ok {
+			return fmt.Errorf("tokens %s and %s are the same", other.Name, t.Name)
+		}
+		tokens[sum] = t
+	}
+
+	a.mu.Lock()
+	a.tokens = tokens
+	a.mu.Unlock()
+	return nil
+}
+
+// ServerOptions returns the interceptors enforcing the tokens.
+func (a *Authorizer) ServerOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(a.unary),
+		grpc.ChainStreamInterceptor(a.stream),
+	}
+}
+
+func (a *Authorizer) unary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	t, err := a.authenticate(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	if !t.Scoped() {
+		return handler(ctx, req)
+	}
+
+	// Ограниченный токен видит только свои процессы
+	switch r := req.(type) {
+	case *gosv.StatusRequest:
+		resp, err := handler(ctx, req)
+		if sr, ok := resp.(*gosv.StatusResponse); ok {
+			sr.Processes = a.filterStatuses(t, sr.Processes)
+		}
+		return resp, err
+	case *gosv.LogsRequest:
+		if len(r.Processes) == 0 {
+			if r.Processes = a.visible(t); len(r.Processes) == 0 {
+				return &gosv.LogsResponse{}, nil
+			}
+		}
+	}
+	if err := a.checkScope(t, req); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (a *Authorizer) stream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	t, err := a.authenticate(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	if !t.Scoped() {
+		return handler(srv, ss)
+	}
+	return handler(srv, &scopedStream{ServerStream: ss, a: a, token: t})
+}
+
+// scopedStream checks the request of a streaming call once it is read.
+type scopedStream struct {
+	grpc.ServerStream
+	a     *Authorizer
+	token config.TokenConfig
+}
+
+func (s *scopedStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return s.a.checkScope(s.token, m)
+}
+
+// authenticate finds the token of the call and checks its role.
+func (a *Authorizer) authenticate(ctx context.Context, method string) (config.TokenConfig, error) {
+	t, err := a.lookup(ctx)
+	if err != nil {
+		return t, err
+	}
+	if want := requiredRole(method); !t.Role.Allows(want) {
+		return t, status.Errorf(codes.PermissionDenied, "token %s has role %s, %s needs %s",
+			t.Name, t.Role, method, want)
+	}
+	return t, nil
+}
+
+// lookup finds the token sent with the call.
+func (a *Authorizer) lookup(ctx context.Context) (config.TokenConfig, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	var secret string
+	for _, v := range md.Get("authorization") {
+		if s, ok := strings.CutPrefix(v, "Bearer "); ok {
+			secret = strings.TrimSpace(s)
+			break
+		}
+	}
+	if secret == "" {
+		return config.TokenConfig{}, status.Error(codes.Unauthenticated, "missing bearer token")
+	}
+
+	a.mu.RLock()
+	t, ok := a.tokens[sha256.Sum256([]byte(secret))]
+	a.mu.RUnlock()
+	if !ok {
+		return config.TokenConfig{}, status.Error(codes.Unauthenticated, "invalid token")
+	}
+	return t, nil
+}
+
+// checkScope makes sure that a call of a scoped token only touches its
+// processes. Calls without a target concern all processes and are denied.
+func (a *Authorizer) checkScope(t config.TokenConfig, req any) error {
+	var names, groups []string
+	switch r := req.(type) {
+	case interface{ GetGroup() string }:
+		if r.GetGroup() != "" {
+			groups = append(groups, r.GetGroup())
+		}
+	}
+	switch r := req.(type) {
+	case interface{ GetName() string }:
+		if r.GetName() != "" {
+			names = append(names, r.GetName())
+		}
+	case *gosv.LogsRequest:
+		names = r.Processes
+	case *gosv.ProcessConfigRequest:
+		// Неразборчивую конфигурацию отклонит сам обработчик
+		if pc, err := config.ParseProcess([]byte(r.Config)); err == nil {
+			names = append(names, pc.Name)
+		}
+	}
+	if len(names)+len(groups) == 0 {
+		return status.Errorf(codes.PermissionDenied, "token %s is limited to some processes and must name one", t.Name)
+	}
+
+	for _, group := range groups {
+		if !a.groupAllowed(t, group) {
+			return status.Errorf(codes.PermissionDenied, "token %s may not access group %s", t.Name, group)
+		}
+	}
+	for _, name := range names {
+		if !a.nameAllowed(t, name) {
+			return status.Errorf(codes.PermissionDenied, "token %s may not access %s", t.Name, name)
+		}
+	}
+	return nil
+}
+
+// groupAllowed reports whether the token may act on a group: either the
+// group itself is allowed or every member is.
+func (a *Authorizer) groupAllowed(t config.TokenConfig, group string) bool {
+	if matchAny(t.Groups, group) {
+		return true
+	}
+	members, err := a.sv.GroupMembers(group)
+	if err != nil || len(members) == 0 {
+		return false
+	}
+	return a.allAllowed(t, members)
+}
+
+// nameAllowed reports whether the token may act on everything a name
+// resolves to. Unknown names are judged by the name alone, so that adding
+// a program or getting NotFound works within the scope.
+func (a *Authorizer) nameAllowed(t config.TokenConfig, name string) bool {
+	ids, err := a.sv.ProcessNames(name)
+	if err != nil {
+		return matchAny(t.Processes, name)
+	}
+	return a.allAllowed(t, ids)
+}
+
+func (a *Authorizer) allAllowed(t config.TokenConfig, ids []string) bool {
+	statuses := a.sv.Status()
+	for _, id := range ids {
+		var group string
+		if info, ok := statuses[id]; ok {
+			group = info.Group
+		}
+		if !processAllowed(t, id, group) {
+			return false
+		}
+	}
+	return true
+}
+
+// visible returns the processes the token may see.
+func (a *Authorizer) visible(t config.TokenConfig) []string {
+	var names []string
+	for id, info := range a.sv.Status() {
+		if processAllowed(t, id, info.Group) {
+			names = append(names, id)
+		}
+	}
+	return names
+}
+
+func (a *Authorizer) filterStatuses(t config.TokenConfig, statuses []*gosv.ProcessStatus) []*gosv.ProcessStatus {
+	var kept []*gosv.ProcessStatus
+	for _, ps := range statuses {
+		if processAllowed(t, ps.Name, ps.Group) {
+			kept = append(kept, ps)
+		}
+	}
+	return kept
+}
+
+// processAllowed matches an instance by its own name, its program name
+// and its group.
+func processAllowed(t config.TokenConfig, id, group string) bool {
+	return matchAny(t.Processes, id) || matchAny(t.Processes, programName(id)) ||
+		(group != "" && matchAny(t.Groups, group))
+}
+
+// programName strips the instance number from names like "worker:01".
+func programName(id string) string {
+	i := strings.LastIndexByte(id, ':')
+	if i < 0 || i == len(id)-1 {
+		return id
+	}
+	for _, c := range id[i+1:] {
+		if c < '0' || c > '9' {
+			return id
+		}
+	}
+	return id[:i]
+}
+
+func matchAny(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,207 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/kolkov/gosv/api/gosv"
+	"github.com/kolkov/gosv/internal/config"
+	"github.com/kolkov/gosv/internal/process"
+	"github.com/kolkov/gosv/internal/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// fakeSupervisor knows web in group front, the instances of worker in
+// group back and db without a group.
+type fakeSupervisor struct {
+	service.SupervisorService
+}
+
+var fakeGroups = map[string]string{"web": "front", "worker:00": "back", "worker:01": "back", "db": ""}
+
+func (fakeSupervisor) Status() map[string]*process.ProcessInfo {
+	statuses := make(map[string]*process.ProcessInfo)
+	for id, group := range fakeGroups {
+		statuses[id] = &process.ProcessInfo{Group: group}
+	}
+	return statuses
+}
+
+func (fakeSupervisor) ProcessNames(name string) ([]string, error) {
+	if name == "worker" {
+		return []string{"worker:00", "worker:01"}, nil
+	}
+	if _, ok := fakeGroups[name]; ok {
+		return []string{name}, nil
+	}
+	return nil, fmt.Errorf("process %w: %s", process.ErrNotFound, name)
+}
+
+func (fakeSupervisor) GroupMembers(group string) ([]string, error) {
+	var members []string
+	for id, g := range fakeGroups {
+		if g == group {
+			members = append(members, id)
+		}
+	}
+	if members == nil {
+		return nil, fmt.Errorf("group %w: %s", process.ErrNotFound, group)
+	}
+	slices.Sort(members)
+	return members, nil
+}
+
+func newTestAuthorizer(t *testing.T) *Authorizer {
+	t.Helper()
+	a, err := NewAuthorizer(fakeSupervisor{}, config.AuthConfig{Tokens: []config.TokenConfig{
+		{Name: "viewer", Token: "v-secret", Role: config.RoleViewer},
+		{Name: "operator", Token: "o-secret", Role: config.RoleOperator},
+		{Name: "admin", Token: "a-secret", Role: config.RoleAdmin},
+		{Name: "deploy", Token: "d-secret", Role: config.RoleOperator,
+			Processes: []string{"worker"}, Groups: []string{"front"}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func withToken(secret string) context.Context {
+	ctx := context.Background()
+	if secret == "" {
+		return ctx
+	}
+	return metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", "Bearer "+secret))
+}
+
+func TestAuthorizerUnary(t *testing.T) {
+	a := newTestAuthorizer(t)
+	tests := []struct {
+		name   string
+		token  string
+		method string
+		req    any
+		want   codes.Code
+	}{
+		{"no token", "", gosv.Supervisor_GetStatus_FullMethodName, &gosv.StatusRequest{}, codes.Unauthenticated},
+		{"unknown token", "nope", gosv.Supervisor_GetStatus_FullMethodName, &gosv.StatusRequest{}, codes.Unauthenticated},
+
+		{"viewer reads status", "v-secret", gosv.Supervisor_GetStatus_FullMethodName, &gosv.StatusRequest{}, codes.OK},
+		{"viewer uses reflection", "v-secret", "/grpc.reflection.v1.ServerReflection/ServerReflectionInfo", nil, codes.OK},
+		{"viewer cannot start", "v-secret", gosv.Supervisor_StartProcess_FullMethodName, &gosv.ProcessRequest{Name: "web"}, codes.PermissionDenied},
+		{"viewer cannot read audit log", "v-secret", gosv.Supervisor_GetAuditLog_FullMethodName, &gosv.AuditRequest{}, codes.PermissionDenied},
+		{"operator starts", "o-secret", gosv.Supervisor_StartProcess_FullMethodName, &gosv.ProcessRequest{Name: "web"}, codes.OK},
+		{"operator reloads", "o-secret", gosv.Supervisor_ReloadConfig_FullMethodName, &gosv.ReloadRequest{}, codes.OK},
+		{"operator cannot add", "o-secret", gosv.Supervisor_AddProcess_FullMethodName, &gosv.ProcessConfigRequest{}, codes.PermissionDenied},
+		{"operator cannot call unlisted method", "o-secret", "/gosv.Supervisor/Unknown", nil, codes.PermissionDenied},
+		{"admin adds", "a-secret", gosv.Supervisor_AddProcess_FullMethodName, &gosv.ProcessConfigRequest{}, codes.OK},
+
+		{"scoped starts program", "d-secret", gosv.Supervisor_StartProcess_FullMethodName, &gosv.ProcessRequest{Name: "worker"}, codes.OK},
+		{"scoped starts instance", "d-secret", gosv.Supervisor_StartProcess_FullMethodName, &gosv.ProcessRequest{Name: "worker:01"}, codes.OK},
+		{"scoped starts process of its group", "d-secret", gosv.Supervisor_StartProcess_FullMethodName, &gosv.ProcessRequest{Name: "web"}, codes.OK},
+		{"scoped starts its group", "d-secret", gosv.Supervisor_StartProcess_FullMethodName, &gosv.ProcessRequest{Group: "front"}, codes.OK},
+		{"scoped starts group of its processes", "d-secret", gosv.Supervisor_StopProcess_FullMethodName, &gosv.ProcessRequest{Group: "back"}, codes.OK},
+		{"scoped cannot start other process", "d-secret", gosv.Supervisor_StartProcess_FullMethodName, &gosv.ProcessRequest{Name: "db"}, codes.PermissionDenied},
+		{"scoped cannot start unknown process", "d-secret", gosv.Supervisor_StartProcess_FullMethodName, &gosv.ProcessRequest{Name: "cron"}, codes.PermissionDenied},
+		{"scoped cannot signal other process", "d-secret", gosv.Supervisor_SignalProcess_FullMethodName, &gosv.SignalRequest{Name: "db", Signal: "HUP"}, codes.PermissionDenied},
+		{"scoped cannot call without target", "d-secret", gosv.Supervisor_ReloadConfig_FullMethodName, &gosv.ReloadRequest{}, codes.PermissionDenied},
+		{"scoped cannot read other logs", "d-secret", gosv.Supervisor_GetLogs_FullMethodName, &gosv.LogsRequest{Processes: []string{"worker", "db"}}, codes.PermissionDenied},
+		{"scoped role still applies", "d-secret", gosv.Supervisor_RemoveProcess_FullMethodName, &gosv.RemoveProcessRequest{Name: "worker"}, codes.PermissionDenied},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			handler := func(ctx context.Context, req any) (any, error) {
+				called = true
+				return nil, nil
+			}
+			_, err := a.unary(withToken(tt.token), tt.req, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+			if got := status.Code(err); got != tt.want {
+				t.Fatalf("code %v (%v), want %v", got, err, tt.want)
+			}
+			if called != (tt.want == codes.OK) {
+				t.Errorf("handler called: %v", called)
+			}
+		})
+	}
+}
+
+func TestAuthorizerFiltersForScopedToken(t *testing.T) {
+	a := newTestAuthorizer(t)
+	info := func(method string) *grpc.UnaryServerInfo { return &grpc.UnaryServerInfo{FullMethod: method} }
+
+	resp, err := a.unary(withToken("d-secret"), &gosv.StatusRequest{}, info(gosv.Supervisor_GetStatus_FullMethodName),
+		func(ctx context.Context, req any) (any, error) {
+			sr := &gosv.StatusResponse{}
+			for _, id := range []string{"db", "web", "worker:00", "worker:01"} {
+				sr.Processes = append(sr.Processes, &gosv.ProcessStatus{Name: id, Group: fakeGroups[id]})
+			}
+			return sr, nil
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, ps := range resp.(*gosv.StatusResponse).Processes {
+		names = append(names, ps.Name)
+	}
+	if want := []string{"web", "worker:00", "worker:01"}; !slices.Equal(names, want) {
+		t.Errorf("status shows %v, want %v", names, want)
+	}
+
+	// Логи без фильтра ограничиваются видимыми процессами
+	var asked []string
+	_, err = a.unary(withToken("d-secret"), &gosv.LogsRequest{}, info(gosv.Supervisor_GetLogs_FullMethodName),
+		func(ctx context.Context, req any) (any, error) {
+			asked = slices.Sorted(slices.Values(req.(*gosv.LogsRequest).Processes))
+			return &gosv.LogsResponse{}, nil
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"web", "worker:00", "worker:01"}; !slices.Equal(asked, want) {
+		t.Errorf("logs asked for %v, want %v", asked, want)
+	}
+}
+
+func TestAuthorizerScopedStream(t *testing.T) {
+	a := newTestAuthorizer(t)
+	for _, tt := range []struct {
+		req  *gosv.StreamLogsRequest
+		want codes.Code
+	}{
+		{&gosv.StreamLogsRequest{Name: "worker"}, codes.OK},
+		{&gosv.StreamLogsRequest{Group: "front"}, codes.OK},
+		{&gosv.StreamLogsRequest{Name: "db"}, codes.PermissionDenied},
+		{&gosv.StreamLogsRequest{}, codes.PermissionDenied},
+	} {
+		ss := &fakeStream{ctx: withToken("d-secret"), req: tt.req}
+		err := a.stream(nil, ss, &grpc.StreamServerInfo{FullMethod: gosv.Supervisor_StreamLogs_FullMethodName},
+			func(srv any, ss grpc.ServerStream) error {
+				return ss.RecvMsg(&gosv.StreamLogsRequest{})
+			})
+		if got := status.Code(err); got != tt.want {
+			t.Errorf("%v: code %v (%v), want %v", tt.req, got, err, tt.want)
+		}
+	}
+}
+
+// fakeStream delivers one request.
+type fakeStream struct {
+	grpc.ServerStream
+	ctx context.Context
+	req *gosv.StreamLogsRequest
+}
+
+func (s *fakeStream) Context() context.Context { return s.ctx }
+
+func (s *fakeStream) RecvMsg(m any) error {
+	proto.Merge(m.(proto.Message), s.req)
+	return nil
+}
@@ -0,0 +1,42 @@
+package api
+
+import (
+	"context"
+	"errors"
+
+	"github.com/kolkov/gosv/internal/config"
+	"github.com/kolkov/gosv/internal/process"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// statusError converts an error from the supervisor into a gRPC status
+// error, so that clients can tell the failures apart by code.
+func statusError(err error) error {
+	code := codes.Unknown
+	switch {
+	case errors.Is(err, process.ErrNotFound):
+		code = codes.NotFound
+	case errors.Is(err, process.ErrAlreadyExists):
+		code = codes.AlreadyExists
+	case errors.Is(err, config.ErrInvalid):
+		code = codes.InvalidArgument
+	case errors.Is(err, process.ErrInvalidSignal):
+		code = codes.InvalidArgument
+	case errors.Is(err, process.ErrAlreadyRunning), errors.Is(err, process.ErrNotRunning):
+		code = codes.FailedPrecondition
+	case errors.Is(err, process.ErrStartFailed):
+		code = codes.Aborted
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	}
+	return status.Error(code, err.Error())
+}
+
+// checkTarget rejects control requests that name no process or group.
+func checkTarget(name, group string) error {
+	if name == "" && group == "" {
+		return status.Error(codes.InvalidArgument, "name or group is required")
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package api
+
+import (
+	"context"
+	"strings"
+
+	"github.com/kolkov/gosv/internal/metrics"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// countRequests returns interceptors counting every call in m, rejected
+// ones included; with a nil m they count nothing.
+func countRequests(m *metrics.Metrics) []grpc.ServerOption {
+	if m == nil {
+		return nil
+	}
+	// "/gosv.Supervisor/StartProcess" -> "StartProcess"
+	method := func(full string) string {
+		return full[strings.LastIndexByte(full, '/')+1:]
+	}
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			resp, err := handler(ctx, req)
+			m.CountRequest(method(info.FullMethod), status.Code(err).String())
+			return resp, err
+		}),
+		grpc.ChainStreamInterceptor(func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			err := handler(srv, ss)
+			m.CountRequest(method(info.FullMethod), status.Code(err).String())
+			return err
+		}),
+	}
+}
@@ -0,0 +1,32 @@
+package api
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"syscall"
+
+	"github.com/kolkov/gosv/internal/audit"
+)
+
+// peerActor names the user and process on the other end of a Unix socket.
+func peerActor(conn net.Conn) string {
+	uc, ok := conn.(*net.UnixConn)
+	if !ok {
+		return ""
+	}
+	raw, err := uc.SyscallConn()
+	if err != nil {
+		return ""
+	}
+
+	var cred *syscall.Ucred
+	var credErr error
+	err = raw.Control(func(fd uintptr) {
+		cred, credErr = syscall.GetsockoptUcred(int(fd), syscall.SOL_SOCKET, syscall.SO_PEERCRED)
+	})
+	if err != nil || credErr != nil {
+		return ""
+	}
+	return fmt.Sprintf("%s pid:%d", audit.UserActor(strconv.Itoa(int(cred.Uid))), cred.Pid)
+}
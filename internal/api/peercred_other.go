@@ -0,0 +1,11 @@
+//go:build !linux
+
+package api
+
+import "net"
+
+// peerActor is not supported on this platform: socket clients are
+// recorded as "local".
+func peerActor(conn net.Conn) string {
+	return ""
+}
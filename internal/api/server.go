@@ -5,11 +5,22 @@ import (
 	"google.golang.org/grpc/reflection"
 	"log"
 	"net"
+	"slices"
+	"sort"
 	"time"
 
 	"github.com/kolkov/gosv/api/gosv"
+	"github.com/kolkov/gosv/internal/config"
+	"github.com/kolkov/gosv/internal/logging"
+	"github.com/kolkov/gosv/internal/metrics"
+	"github.com/kolkov/gosv/internal/process"
 	"github.com/kolkov/gosv/internal/service"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type Server struct {
@@ -22,28 +33,60 @@ func NewServer(sv service.SupervisorService) *Server {
 }
 
 func (s *Server) StartProcess(ctx context.Context, req *gosv.ProcessRequest) (*gosv.Response, error) {
-	// Перед запуском остановим процесс, если он уже работает
-	_ = s.sv.StopProcess(req.Name) // Игнорируем ошибку если процесс не найден
-	time.Sleep(100 * time.Millisecond)
+	if err := checkTarget(req.Name, req.Group); err != nil {
+		return nil, err
+	}
 
-	if err := s.sv.StartProcess(req.Name); err != nil {
-		return &gosv.Response{Success: false, Message: err.Error()}, nil
+	// Уже работающие процессы перезапускаем только по явному force
+	var err error
+	switch {
+	case req.Group != "" && req.Force:
+		err = s.sv.RestartGroup(req.Group)
+	case req.Group != "":
+		err = s.sv.StartGroup(req.Group)
+	case req.Force:
+		err = s.sv.RestartProcess(req.Name)
+	default:
+		err = s.sv.StartProcess(req.Name)
+	}
+	if err != nil {
+		return nil, statusError(err)
 	}
-	return &gosv.Response{Success: true, Message: "Process started"}, nil
+	return s.response(subject(req.Group)+" started", req.Name, req.Group), nil
 }
 
 func (s *Server) StopProcess(ctx context.Context, req *gosv.ProcessRequest) (*gosv.Response, error) {
-	if err := s.sv.StopProcess(req.Name); err != nil {
-		return &gosv.Response{Success: false, Message: err.Error()}, nil
+	if err := checkTarget(req.Name, req.Group); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if req.Group != "" {
+		err = s.sv.StopGroup(req.Group)
+	} else {
+		err = s.sv.StopProcess(req.Name)
 	}
-	return &gosv.Response{Success: true, Message: "Process stopped"}, nil
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return s.response(subject(req.Group)+" stopped", req.Name, req.Group), nil
 }
 
 func (s *Server) RestartProcess(ctx context.Context, req *gosv.ProcessRequest) (*gosv.Response, error) {
-	if err := s.sv.RestartProcess(req.Name); err != nil {
-		return &gosv.Response{Success: false, Message: err.Error()}, nil
+	if err := checkTarget(req.Name, req.Group); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if req.Group != "" {
+		err = s.sv.RestartGroup(req.Group)
+	} else {
+		err = s.sv.RestartProcess(req.Name)
 	}
-	return &gosv.Response{Success: true, Message: "Process restarted"}, nil
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return s.response(subject(req.Group)+" restarted", req.Name, req.Group), nil
 }
 
 func (s *Server) GetStatus(ctx context.Context, req *gosv.StatusRequest) (*gosv.StatusResponse, error) {
@@ -53,35 +96,422 @@ func (s *Server) GetStatus(ctx context.Context, req *gosv.StatusRequest) (*gosv.
 	}
 
 	for name, info := range statuses {
-		pbStatus := &gosv.ProcessStatus{
-			Name:     name,
-			Status:   string(info.Status),
-			Pid:      int32(info.PID),
-			Restarts: int32(info.Restarts),
+		resp.Processes = append(resp.Processes, processStatusToProto(name, info))
+	}
+
+	return resp, nil
+}
+
+func (s *Server) SignalProcess(ctx context.Context, req *gosv.SignalRequest) (*gosv.Response, error) {
+	if err := checkTarget(req.Name, req.Group); err != nil {
+		return nil, err
+	}
+
+	name := req.Name
+	if req.Group != "" {
+		if _, err := s.sv.GroupMembers(req.Group); err != nil {
+			return nil, statusError(err)
 		}
-		if info.ExitError != nil {
-			pbStatus.Error = info.ExitError.Error()
+		name = req.Group + ":*"
+	}
+
+	if err := s.sv.SignalProcess(name, req.Signal); err != nil {
+		return nil, statusError(err)
+	}
+	return s.response("Signal sent", req.Name, req.Group), nil
+}
+
+func (s *Server) ReloadConfig(ctx context.Context, req *gosv.ReloadRequest) (*gosv.ChangeResponse, error) {
+	changes, err := s.sv.Reload(req.DryRun)
+	if err != nil {
+		// Ошибки процессов отдаём как есть, остальное - невалидный файл
+		if st := statusError(err); status.Code(st) != codes.Unknown {
+			return nil, st
 		}
-		resp.Processes = append(resp.Processes, pbStatus)
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return s.changeResponse("Configuration reloaded", changes, req.DryRun), nil
+}
+
+func (s *Server) AddProcess(ctx context.Context, req *gosv.ProcessConfigRequest) (*gosv.ChangeResponse, error) {
+	pc, err := config.ParseProcess([]byte(req.Config))
+	if err != nil {
+		return nil, statusError(err)
+	}
+	changes, err := s.sv.AddProcess(pc, req.DryRun)
+	if err != nil {
+		return nil, statusError(err)
 	}
+	return s.changeResponse("Process added", changes, req.DryRun), nil
+}
 
+func (s *Server) UpdateProcess(ctx context.Context, req *gosv.ProcessConfigRequest) (*gosv.ChangeResponse, error) {
+	pc, err := config.ParseProcess([]byte(req.Config))
+	if err != nil {
+		return nil, statusError(err)
+	}
+	changes, err := s.sv.UpdateProcess(pc, req.DryRun)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return s.changeResponse("Process updated", changes, req.DryRun), nil
+}
+
+func (s *Server) RemoveProcess(ctx context.Context, req *gosv.RemoveProcessRequest) (*gosv.ChangeResponse, error) {
+	if req.Name == "" {
+		return nil, status.Error(codes.InvalidArgument, "name is required")
+	}
+	changes, err := s.sv.RemoveProcess(req.Name, req.DryRun)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return s.changeResponse("Process removed", changes, req.DryRun), nil
+}
+
+// changeResponse reports a change set together with the status of the
+// added and updated processes.
+func (s *Server) changeResponse(message string, changes config.ChangeSet, dryRun bool) *gosv.ChangeResponse {
+	resp := &gosv.ChangeResponse{
+		Message: message,
+		DryRun:  dryRun,
+		Changes: &gosv.ChangeSet{
+			Added:     changes.Added,
+			Updated:   changes.Updated,
+			Removed:   changes.Removed,
+			Unchanged: changes.Unchanged,
+		},
+	}
+	if dryRun {
+		resp.Message = "Dry run, nothing changed"
+		return resp
+	}
+
+	statuses := s.sv.Status()
+	for _, program := range slices.Concat(changes.Added, changes.Updated) {
+		names, err := s.sv.ProcessNames(program)
+		if err != nil {
+			continue
+		}
+		for _, name := range names {
+			if info, ok := statuses[name]; ok {
+				resp.Processes = append(resp.Processes, processStatusToProto(name, info))
+			}
+		}
+	}
+	return resp
+}
+
+// response reports success together with the resulting status of the
+// processes addressed by name or group, or of all processes.
+func (s *Server) response(message, name, group string) *gosv.Response {
+	resp := &gosv.Response{Success: true, Message: message}
+
+	statuses := s.sv.Status()
+	names, err := s.selectProcesses(name, group)
+	if err != nil {
+		return resp
+	}
+	if names == nil {
+		for name := range statuses {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+	for _, name := range names {
+		if info, ok := statuses[name]; ok {
+			resp.Processes = append(resp.Processes, processStatusToProto(name, info))
+		}
+	}
+	return resp
+}
+
+func subject(group string) string {
+	if group != "" {
+		return "Group"
+	}
+	return "Process"
+}
+
+func (s *Server) GetLogs(ctx context.Context, req *gosv.LogsRequest) (*gosv.LogsResponse, error) {
+	filter := logging.Filter{Processes: req.Processes}
+	if req.Level != "" {
+		level, err := logging.ParseLevel(req.Level)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		filter.MinLevel = level
+	}
+	for _, stream := range req.Streams {
+		filter.Streams = append(filter.Streams, logging.Stream(stream))
+	}
+	if req.Since != nil {
+		filter.Since = req.Since.AsTime()
+	}
+
+	records := s.sv.Logs(filter, int(req.Limit))
+	resp := &gosv.LogsResponse{Records: make([]*gosv.LogRecord, 0, len(records))}
+	for _, rec := range records {
+		resp.Records = append(resp.Records, logRecordToProto(rec))
+	}
 	return resp, nil
 }
 
-func StartGRPCServer(sv service.SupervisorService, port string) {
-	lis, err := net.Listen("tcp", ":"+port)
+func (s *Server) StreamLogs(req *gosv.StreamLogsRequest, stream gosv.Supervisor_StreamLogsServer) error {
+	var filter logging.Filter
+	var err error
+	if filter.Processes, err = s.selectProcesses(req.Name, req.Group); err != nil {
+		return err
+	}
+	for _, name := range req.Streams {
+		switch st := logging.Stream(name); st {
+		case logging.StreamSupervisor, logging.StreamStdout, logging.StreamStderr:
+			filter.Streams = append(filter.Streams, st)
+		default:
+			return status.Errorf(codes.InvalidArgument, "unknown stream %q", name)
+		}
+	}
+	if req.Since != nil {
+		filter.Since = req.Since.AsTime()
+	}
+
+	// Подписываемся до чтения буфера, чтобы не потерять записи между ними
+	var live <-chan logging.Record
+	if req.Follow {
+		records, cancel := s.sv.SubscribeLogs(filter)
+		defer cancel()
+		live = records
+	}
+
+	sent := make(map[uint64]bool)
+	if req.Tail != 0 {
+		limit := int(req.Tail)
+		if limit < 0 {
+			limit = 0
+		}
+		for _, rec := range s.sv.Logs(filter, limit) {
+			if err := stream.Send(logRecordToProto(rec)); err != nil {
+				return err
+			}
+			sent[rec.Seq] = true
+		}
+	}
+	if !req.Follow {
+		return nil
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case rec := <-live:
+			if sent[rec.Seq] {
+				delete(sent, rec.Seq)
+				continue
+			}
+			if err := stream.Send(logRecordToProto(rec)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (s *Server) WatchEvents(req *gosv.WatchRequest, stream gosv.Supervisor_WatchEventsServer) error {
+	names, err := s.selectProcesses(req.Name, req.Group)
+	if err != nil {
+		return err
+	}
+	selected := func(name string) bool {
+		return names == nil || slices.Contains(names, name)
+	}
+
+	states := make(map[process.Status]bool, len(req.States))
+	for _, name := range req.States {
+		st, err := process.ParseStatus(name)
+		if err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		states[st] = true
+	}
+	wanted := func(st process.Status) bool {
+		return len(states) == 0 || states[st]
+	}
+
+	// Подписываемся до снимка, чтобы не пропустить переходы между ними
+	events, cancel := s.sv.SubscribeEvents()
+	defer cancel()
+
+	if req.Snapshot {
+		statuses := s.sv.Status()
+		procNames := make([]string, 0, len(statuses))
+		for name := range statuses {
+			procNames = append(procNames, name)
+		}
+		sort.Strings(procNames)
+
+		now := time.Now()
+		var snapshot []*gosv.ProcessEvent
+		for _, name := range procNames {
+			info := statuses[name]
+			if !selected(name) || !wanted(info.Status) {
+				continue
+			}
+			ev := &gosv.ProcessEvent{
+				Time:     timestamppb.New(now),
+				Process:  name,
+				Group:    info.Group,
+				To:       string(info.Status),
+				Pid:      int32(info.PID),
+				ExitCode: int32(info.ExitCode),
+				Snapshot: true,
+			}
+			if info.ExitError != nil {
+				ev.Error = info.ExitError.Error()
+			}
+			snapshot = append(snapshot, ev)
+		}
+		for i, ev := range snapshot {
+			ev.SnapshotEnd = i == len(snapshot)-1
+			if err := stream.Send(ev); err != nil {
+				return err
+			}
+		}
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case e, ok := <-events:
+			if !ok {
+				return nil
+			}
+			if !selected(e.Process) || !wanted(e.To) {
+				continue
+			}
+			if err := stream.Send(eventToProto(e)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// selectProcesses resolves a name or group from a request into process
+// names; nil means all processes.
+func (s *Server) selectProcesses(name, group string) ([]string, error) {
+	var names []string
+	var err error
+	switch {
+	case group != "":
+		names, err = s.sv.GroupMembers(group)
+	case name != "":
+		names, err = s.sv.ProcessNames(name)
+	}
 	if err != nil {
-		log.Fatalf("failed to listen: %v", err)
+		return nil, statusError(err)
+	}
+	return names, nil
+}
+
+func processStatusToProto(name string, info *process.ProcessInfo) *gosv.ProcessStatus {
+	ps := &gosv.ProcessStatus{
+		Name:         name,
+		Status:       string(info.Status),
+		Pid:          int32(info.PID),
+		Restarts:     int32(info.Restarts),
+		StartRetries: int32(info.StartRetries),
+		Ready:        info.Ready,
+		Group:        info.Group,
+		ExitCode:     int32(info.ExitCode),
+		MaxRestarts:  int32(info.MaxRestarts),
+	}
+	if !info.StartTime.IsZero() {
+		ps.StartTime = timestamppb.New(info.StartTime)
+	}
+	if info.RestartWindow > 0 {
+		ps.RestartWindow = durationpb.New(info.RestartWindow)
+	}
+	if info.ExitError != nil {
+		ps.Error = info.ExitError.Error()
+	}
+	if u := info.Usage; u.Sampled() {
+		ps.Usage = &gosv.ResourceUsage{
+			CpuPercent: u.CPUPercent,
+			CpuTime:    durationpb.New(u.CPUTime),
+			RssBytes:   u.RSS,
+			Threads:    int32(u.Threads),
+			Fds:        int32(u.FDs),
+			ReadBytes:  u.ReadBytes,
+			WriteBytes: u.WriteBytes,
+			Processes:  int32(u.Processes),
+			Time:       timestamppb.New(u.Time),
+		}
+	}
+	return ps
+}
+
+func eventToProto(e process.Event) *gosv.ProcessEvent {
+	ev := &gosv.ProcessEvent{
+		Time:     timestamppb.New(e.Time),
+		Process:  e.Process,
+		Group:    e.Group,
+		From:     string(e.From),
+		To:       string(e.To),
+		Pid:      int32(e.PID),
+		ExitCode: int32(e.ExitCode),
+	}
+	if e.Error != nil {
+		ev.Error = e.Error.Error()
+	}
+	return ev
+}
+
+func logRecordToProto(rec logging.Record) *gosv.LogRecord {
+	return &gosv.LogRecord{
+		Time:    timestamppb.New(rec.Time),
+		Level:   rec.Level.String(),
+		Process: rec.Process,
+		Pid:     int32(rec.PID),
+		Stream:  string(rec.Stream),
+		Message: rec.Message,
 	}
+}
 
-	s := grpc.NewServer()
+func newGRPCServer(sv service.SupervisorService, opts ...grpc.ServerOption) *grpc.Server {
+	s := grpc.NewServer(opts...)
 	gosv.RegisterSupervisorServer(s, NewServer(sv))
 
 	// Включаем рефлексию для использования с grpcurl
 	reflection.Register(s)
+	return s
+}
+
+// ServeGRPC serves the API on a TCP address such as "127.0.0.1:50051",
+// over TLS when certs is set and checking tokens when authz is set. Calls
+// are counted in m unless it is nil. The returned function stops the
+// server.
+func ServeGRPC(sv service.SupervisorService, addr string, certs *CertStore, authz *Authorizer, m *metrics.Metrics) (stop func(), err error) {
+	var opts []grpc.ServerOption
+	if certs != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(certs.TLSConfig())))
+	}
+	opts = append(opts, countRequests(m)...)
+	// Аудит идёт первым, чтобы записать и отклонённые вызовы
+	au := &auditor{sv: sv, source: "grpc", authz: authz}
+	opts = append(opts, grpc.ChainUnaryInterceptor(au.unary))
+	if authz != nil {
+		opts = append(opts, authz.ServerOptions()...)
+	}
 
-	log.Printf("gRPC server listening on :%s", port)
-	if err := s.Serve(lis); err != nil {
-		log.Fatalf("failed to serve: %v", err)
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
 	}
+
+	s := newGRPCServer(sv, opts...)
+	go func() {
+		if err := s.Serve(lis); err != nil {
+			log.Printf("[ERROR] gRPC server: %v", err)
+		}
+	}()
+	return s.Stop, nil
 }
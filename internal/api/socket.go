@@ -0,0 +1,131 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"github.com/kolkov/gosv/api/gosv"
+	"github.com/kolkov/gosv/internal/metrics"
+	"github.com/kolkov/gosv/internal/process"
+	"github.com/kolkov/gosv/internal/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// ErrNoDaemon is returned by DialControlSocket when no daemon listens on
+// the control socket.
+var ErrNoDaemon = errors.New("no gosv daemon running")
+
+// ListenControlSocket opens the control socket with the given
+// permissions. A socket left behind by a daemon that died is replaced; a
+// socket another daemon still serves on is an error.
+func ListenControlSocket(path string, mode os.FileMode) (net.Listener, error) {
+	if fi, err := os.Lstat(path); err == nil {
+		if fi.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("control socket %s exists and is not a socket", path)
+		}
+		if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("another gosv daemon is already running on %s", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("removing stale control socket: %w", err)
+		}
+	}
+
+	lis, err := listenUnix(path, mode)
+	if err != nil {
+		return nil, err
+	}
+	// На платформах без umask права выставляем после создания
+	if err := os.Chmod(path, mode); err != nil {
+		lis.Close()
+		return nil, fmt.Errorf("setting control socket permissions: %w", err)
+	}
+	return lis, nil
+}
+
+// ServeControlSocket serves the API on the control socket, counting calls
+// in m unless it is nil. The returned function stops the server and
+// removes the socket.
+func ServeControlSocket(sv service.SupervisorService, path string, mode os.FileMode, m *metrics.Metrics) (stop func(), err error) {
+	lis, err := ListenControlSocket(path, mode)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := append([]grpc.ServerOption{grpc.Creds(newPeerCredentials())}, countRequests(m)...)
+	au := &auditor{sv: sv, source: "socket"}
+	s := newGRPCServer(sv, append(opts, grpc.ChainUnaryInterceptor(au.unary))...)
+	go s.Serve(lis)
+	return func() {
+		s.Stop()
+		os.Remove(path)
+	}, nil
+}
+
+// DialControlSocket connects to the daemon serving on path. It fails with
+// ErrNoDaemon right away instead of letting RPCs time out.
+func DialControlSocket(path string) (*grpc.ClientConn, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: control socket %s not found", ErrNoDaemon, path)
+	}
+	// Чужой сокет мог создать кто угодно, чтобы подменить ответы демона
+	if uid, ok := socketOwner(fi); ok && uid != 0 && uid != os.Getuid() {
+		return nil, fmt.Errorf("control socket %s is owned by uid %d, not by you or root; refusing to connect", path, uid)
+	}
+	conn, err := net.DialTimeout("unix", path, time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("%w: nothing listens on %s", ErrNoDaemon, path)
+	}
+	conn.Close()
+
+	return grpc.NewClient("passthrough:///gosv",
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", path)
+		}),
+	)
+}
+
+// ProcessInfoFromProto converts a status received from the API back, for
+// printing it the way the daemon does.
+func ProcessInfoFromProto(ps *gosv.ProcessStatus) *process.ProcessInfo {
+	info := &process.ProcessInfo{
+		PID:           int(ps.Pid),
+		Status:        process.Status(ps.Status),
+		Restarts:      int(ps.Restarts),
+		StartRetries:  int(ps.StartRetries),
+		MaxRestarts:   int(ps.MaxRestarts),
+		RestartWindow: ps.RestartWindow.AsDuration(),
+		ExitCode:      int(ps.ExitCode),
+		Ready:         ps.Ready,
+		Group:         ps.Group,
+	}
+	if ps.StartTime != nil {
+		info.StartTime = ps.StartTime.AsTime()
+	}
+	if ps.Error != "" {
+		info.ExitError = errors.New(ps.Error)
+	}
+	if u := ps.Usage; u != nil && u.Time != nil {
+		info.Usage = process.Usage{
+			CPUPercent: u.CpuPercent,
+			CPUTime:    u.CpuTime.AsDuration(),
+			RSS:        u.RssBytes,
+			Threads:    int(u.Threads),
+			FDs:        int(u.Fds),
+			ReadBytes:  u.ReadBytes,
+			WriteBytes: u.WriteBytes,
+			Processes:  int(u.Processes),
+			Time:       u.Time.AsTime(),
+		}
+	}
+	return info
+}
@@ -0,0 +1,26 @@
+//go:build unix
+
+package api
+
+import (
+	"net"
+	"os"
+	"syscall"
+)
+
+// socketOwner returns the uid owning the socket file.
+func socketOwner(fi os.FileInfo) (uid int, ok bool) {
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+	return int(st.Uid), true
+}
+
+// listenUnix creates the socket with its final permissions already in
+// place, so there is no window in which others could connect.
+func listenUnix(path string, mode os.FileMode) (net.Listener, error) {
+	old := syscall.Umask(0777 &^ int(mode))
+	defer syscall.Umask(old)
+	return net.Listen("unix", path)
+}
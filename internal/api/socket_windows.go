@@ -0,0 +1,18 @@
+//go:build windows
+
+package api
+
+import (
+	"net"
+	"os"
+)
+
+// socketOwner is not known on Windows, where the file ACL protects the
+// socket.
+func socketOwner(fi os.FileInfo) (uid int, ok bool) {
+	return 0, false
+}
+
+func listenUnix(path string, mode os.FileMode) (net.Listener, error) {
+	return net.Listen("unix", path)
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/kolkov/gosv/internal/config"
+)
+
+// CertStore holds the server certificate and the client CA for the gRPC
+// listener. Reload re-reads the files; new handshakes use them right away
+// while established connections stay up.
+type CertStore struct {
+	files config.TLSConfig
+
+	mu   sync.RWMutex
+	cert *tls.Certificate
+	cas  *x509.CertPool // nil без mTLS
+}
+
+// NewCertStore loads the files named in cfg.
+func NewCertStore(cfg config.TLSConfig) (*CertStore, error) {
+	s := &CertStore{files: cfg}
+	if err := s.Reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Reload re-reads the certificate, key and client CA. On error the
+// previous ones stay in use.
+func (s *CertStore) Reload() error {
+	cert, err := tls.LoadX509KeyPair(s.files.Cert, s.files.Key)
+	if err != nil {
+		return fmt.Errorf("loading server certificate: %w", err)
+	}
+
+	var cas *x509.CertPool
+	if s.files.ClientCA != "" {
+		pem, err := os.ReadFile(s.files.ClientCA)
+		if err != nil {
+			return fmt.Errorf("loading client CA: %w", err)
+		}
+		cas = x509.NewCertPool()
+		if !cas.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates found in %s", s.files.ClientCA)
+		}
+	}
+
+	s.mu.Lock()
+	s.cert, s.cas = &cert, cas
+	s.mu.Unlock()
+	return nil
+}
+
+// MutualTLS reports whether clients must present a certificate.
+func (s *CertStore) MutualTLS() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.cas != nil
+}
+
+// TLSConfig returns a server config that picks up the current files on
+// every handshake.
+func (s *CertStore) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			s.mu.RLock()
+			defer s.mu.RUnlock()
+
+			cfg := &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*s.cert},
+				// gRPC требует согласования HTTP/2 через ALPN
+				NextProtos: []string{"h2"},
+			}
+			if s.cas != nil {
+				cfg.ClientCAs = s.cas
+				cfg.ClientAuth = tls.RequireAndVerifyClientCert
+			}
+			return cfg, nil
+		},
+	}
+}
@@ -0,0 +1,195 @@
+// Package audit records control actions such as starting, stopping and
+// reloading in an append-only JSON-lines file.
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Results of an action.
+const (
+	ResultOK     = "ok"
+	ResultFailed = "failed"
+	// ResultDenied means the caller was not allowed to do it.
+	ResultDenied = "denied"
+)
+
+// Record is one control action: who did what to which target, and how it
+// ended.
+type Record struct {
+	Time time.Time `json:"time"`
+	// Actor identifies the caller, e.g. "token:deploy" or
+	// "uid:1000(alice) pid:4242".
+	Actor string `json:"actor"`
+	// Source is where the action came from: grpc, socket, tui, cli or
+	// signal.
+	Source string `json:"source"`
+	Action string `json:"action"`
+	Target string `json:"target,omitempty"`
+	// Processes lists the processes a group or program target stood for
+	// at the time.
+	Processes []string `json:"processes,omitempty"`
+	// Detail holds arguments such as the signal name.
+	Detail string `json:"detail,omitempty"`
+	Result string `json:"result"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Filter selects records; zero fields match everything.
+type Filter struct {
+	Actor  string
+	Action string
+	// Target matches the target itself and the processes it stood for;
+	// a program name matches its instances like "worker:01".
+	Target     string
+	Since      time.Time
+	Until      time.Time
+	FailedOnly bool // только failed и denied
+}
+
+// Match reports whether r passes the filter.
+func (f Filter) Match(r Record) bool {
+	switch {
+	case f.Actor != "" && !strings.Contains(r.Actor, f.Actor):
+		return false
+	case f.Action != "" && r.Action != f.Action:
+		return false
+	case f.Target != "" && !f.matchTarget(r):
+		return false
+	case !f.Since.IsZero() && r.Time.Before(f.Since):
+		return false
+	case !f.Until.IsZero() && r.Time.After(f.Until):
+		return false
+	case f.FailedOnly && r.Result == ResultOK:
+		return false
+	}
+	return true
+}
+
+func (f Filter) matchTarget(r Record) bool {
+	for _, name := range append([]string{r.Target}, r.Processes...) {
+		if name == f.Target || strings.HasPrefix(name, f.Target+":") {
+			return true
+		}
+	}
+	return false
+}
+
+// Log appends records to a file and reads them back. It is safe for
+// concurrent use.
+type Log struct {
+	mu   sync.Mutex
+	path string
+	f    *os.File
+}
+
+// Open opens or creates the audit file at path; records are only ever
+// appended to it.
+func Open(path string) (*Log, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &Log{path: path, f: f}, nil
+}
+
+// Write appends r, setting its time if it has none. The record is synced
+// to disk before Write returns.
+func (l *Log) Write(r Record) error {
+	if r.Time.IsZero() {
+		r.Time = time.Now()
+	}
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, err := l.f.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	return l.f.Sync()
+}
+
+// Read returns the records matching f, oldest first; with limit > 0 only
+// the most recent limit records. Lines that are not valid records are
+// skipped.
+func (l *Log) Read(f Filter, limit int) ([]Record, error) {
+	file, err := os.Open(l.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var records []Record
+	sc := bufio.NewScanner(file)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		var r Record
+		if json.Unmarshal(sc.Bytes(), &r) != nil || !f.Match(r) {
+			continue
+		}
+		records = append(records, r)
+		// Храним не больше limit последних записей
+		if limit > 0 && len(records) > 2*limit {
+			records = append(records[:0], records[len(records)-limit:]...)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("reading audit log: %w", err)
+	}
+	if limit > 0 && len(records) > limit {
+		records = records[len(records)-limit:]
+	}
+	return records, nil
+}
+
+// Close closes the file.
+func (l *Log) Close() error {
+	return l.f.Close()
+}
+
+// UserActor describes a local user by uid, with the user name if it is
+// known.
+func UserActor(uid string) string {
+	if u, err := user.LookupId(uid); err == nil {
+		return fmt.Sprintf("uid:%s(%s)", uid, u.Username)
+	}
+	return "uid:" + uid
+}
+
+// LocalActor describes the user gosv runs as, for actions taken in the
+// daemon itself such as the TUI.
+func LocalActor() string {
+	u, err := user.Current()
+	if err != nil {
+		return "local"
+	}
+	return fmt.Sprintf("uid:%s(%s)", u.Uid, u.Username)
+}
+
+// NewRecord describes an action that ended with err, nil for success.
+func NewRecord(actor, source, action, target string, err error) Record {
+	r := Record{Actor: actor, Source: source, Action: action, Target: target, Result: ResultOK}
+	if err != nil {
+		r.Result, r.Error = ResultFailed, err.Error()
+	}
+	return r
+}
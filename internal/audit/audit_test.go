@@ -0,0 +1,74 @@
+package audit
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogWriteRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit", "audit.log")
+	l, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	records := []Record{
+		{Time: start, Actor: "token:deploy", Source: "grpc", Action: "start", Target: "worker", Processes: []string{"worker:00", "worker:01"}, Result: ResultOK},
+		NewRecord("uid:0(root)", "socket", "stop", "web", errors.New("process not running")),
+		{Time: start.Add(2 * time.Minute), Actor: "token:viewer", Source: "grpc", Action: "stop", Target: "db", Result: ResultDenied},
+	}
+	records[1].Time = start.Add(time.Minute)
+	for _, r := range records {
+		if err := l.Write(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != len(records) {
+		t.Fatalf("%d lines in the file, want %d", len(lines), len(records))
+	}
+	want := `{"time":"2026-01-02T03:05:05Z","actor":"uid:0(root)","source":"socket","action":"stop","target":"web","result":"failed","error":"process not running"}`
+	if lines[1] != want {
+		t.Errorf("line 2 is\n%s\nwant\n%s", lines[1], want)
+	}
+
+	for _, tt := range []struct {
+		name   string
+		filter Filter
+		limit  int
+		want   []string // actor записей по порядку
+	}{
+		{"all", Filter{}, 0, []string{"token:deploy", "uid:0(root)", "token:viewer"}},
+		{"limit", Filter{}, 2, []string{"uid:0(root)", "token:viewer"}},
+		{"instance of a program", Filter{Target: "worker:01"}, 0, []string{"token:deploy"}},
+		{"program", Filter{Target: "worker"}, 0, []string{"token:deploy"}},
+		{"failed only", Filter{FailedOnly: true}, 0, []string{"uid:0(root)", "token:viewer"}},
+		{"action and actor", Filter{Action: "stop", Actor: "token"}, 0, []string{"token:viewer"}},
+		{"since", Filter{Since: start.Add(time.Minute)}, 0, []string{"uid:0(root)", "token:viewer"}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := l.Read(tt.filter, tt.limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var actors []string
+			for _, r := range got {
+				actors = append(actors, r.Actor)
+			}
+			if strings.Join(actors, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("Read = %v, want %v", actors, tt.want)
+			}
+		})
+	}
+}
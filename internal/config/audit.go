@@ -0,0 +1,23 @@
+package config
+
+import "path/filepath"
+
+// DefaultAuditFile is used when the config file names no audit log.
+const DefaultAuditFile = "gosv-audit.jsonl"
+
+// AuditConfig configures the record of control actions: who started,
+// stopped, restarted, signalled or reloaded what, and when.
+type AuditConfig struct {
+	// File is the JSON-lines file records are appended to,
+	// gosv-audit.jsonl in the working directory by default.
+	File string `yaml:"file,omitempty"`
+}
+
+func applyAuditDefaults(a *AuditConfig) {
+	if a.File == "" {
+		a.File = DefaultAuditFile
+	}
+	if abs, err := filepath.Abs(a.File); err == nil {
+		a.File = abs
+	}
+}
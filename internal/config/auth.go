@@ -0,0 +1,111 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// Role decides which API calls a token may make. Each role includes the
+// rights of the ones before it.
+type Role string
+
+const (
+	// RoleViewer may read status, logs and events.
+	RoleViewer Role = "viewer"
+	// RoleOperator may also start, stop, restart and signal processes and
+	// reload the config file.
+	RoleOperator Role = "operator"
+	// RoleAdmin may also add, update and remove programs at runtime.
+	RoleAdmin Role = "admin"
+)
+
+// AuthConfig lists the tokens accepted by the gRPC listener. Without
+// tokens every client that gets through TLS has full access. The control
+// socket is protected by its file permissions and needs no token.
+type AuthConfig struct {
+	Tokens []TokenConfig `yaml:"tokens,omitempty"`
+}
+
+// TokenConfig is one API token. Clients send it as
+// "authorization: Bearer <token>" metadata.
+type TokenConfig struct {
+	// Name identifies the client in logs.
+	Name string `yaml:"name"`
+	// Token is the secret itself; TokenFile keeps it out of the config.
+	// Exactly one of them has to be set. The file is read again on SIGHUP.
+	Token     string `yaml:"token,omitempty"`
+	TokenFile string `yaml:"token_file,omitempty"`
+	Role      Role   `yaml:"role"`
+
+	// Processes and Groups limit the token to these programs and groups;
+	// shell patterns like "web-*" are allowed. Without both, the token
+	// applies to all processes.
+	Processes []string `yaml:"processes,omitempty"`
+	Groups    []string `yaml:"groups,omitempty"`
+}
+
+// Scoped reports whether the token is limited to some processes or groups.
+func (t TokenConfig) Scoped() bool {
+	return len(t.Processes)+len(t.Groups) > 0
+}
+
+// Allows reports whether role r includes the rights of role want.
+func (r Role) Allows(want Role) bool {
+	return r.level() >= want.level()
+}
+
+func (r Role) level() int {
+	switch r {
+	case RoleViewer:
+		return 1
+	case RoleOperator:
+		return 2
+	case RoleAdmin:
+		return 3
+	}
+	return 0
+}
+
+func applyAuthDefaults(a *AuthConfig) error {
+	var errs []error
+	seen := make(map[string]bool)
+	for i := range a.Tokens {
+		t := &a.Tokens[i]
+		if t.Name == "" {
+			errs = append(errs, fmt.Errorf("auth: token #%d has no name", i+1))
+			continue
+		}
+		if seen[t.Name] {
+			errs = append(errs, fmt.Errorf("auth: duplicate token name %q", t.Name))
+		}
+		seen[t.Name] = true
+
+		if t.Role.level() == 0 {
+			errs = append(errs, fmt.Errorf("auth: token %s: unknown role %q: want viewer, operator or admin", t.Name, t.Role))
+		}
+
+		switch {
+		case t.Token != "" && t.TokenFile != "":
+			errs = append(errs, fmt.Errorf("auth: token %s: set either token or token_file, not both", t.Name))
+		case t.TokenFile != "":
+			if abs, err := filepath.Abs(t.TokenFile); err == nil {
+				t.TokenFile = abs
+			}
+			if _, err := os.Stat(t.TokenFile); err != nil {
+				errs = append(errs, fmt.Errorf("auth: token %s: %w", t.Name, err))
+			}
+		case t.Token == "":
+			errs = append(errs, fmt.Errorf("auth: token %s: token or token_file is required", t.Name))
+		}
+
+		for _, pattern := range append(append([]string{}, t.Processes...), t.Groups...) {
+			if _, err := path.Match(pattern, ""); err != nil {
+				errs = append(errs, fmt.Errorf("auth: token %s: bad pattern %q", t.Name, pattern))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
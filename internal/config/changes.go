@@ -0,0 +1,167 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrInvalid is wrapped by the errors of ParseProcess, WithProcess and
+// WithoutProcess when the result would not be a valid configuration.
+var ErrInvalid = errors.New("invalid configuration")
+
+// ChangeSet lists the programs that differ between two configurations.
+type ChangeSet struct {
+	Added     []string
+	Updated   []string
+	Removed   []string
+	Unchanged []string
+}
+
+// Empty reports whether applying the change set touches no process.
+func (c ChangeSet) Empty() bool {
+	return len(c.Added)+len(c.Updated)+len(c.Removed) == 0
+}
+
+func (c ChangeSet) String() string {
+	return fmt.Sprintf("%d added, %d updated, %d removed, %d unchanged",
+		len(c.Added), len(c.Updated), len(c.Removed), len(c.Unchanged))
+}
+
+// Diff compares the programs of two normalized configurations. A program
+// is updated when any of its settings changed; moving it to another group
+// needs no restart and leaves it unchanged.
+func Diff(prev, next *Config) ChangeSet {
+	old := make(map[string]ProcessConfig, len(prev.Processes))
+	for _, p := range prev.Processes {
+		old[p.Name] = p
+	}
+
+	var cs ChangeSet
+	kept := make(map[string]bool, len(next.Processes))
+	for _, p := range next.Processes {
+		kept[p.Name] = true
+		o, ok := old[p.Name]
+		switch {
+		case !ok:
+			cs.Added = append(cs.Added, p.Name)
+		case !sameProcess(o, p):
+			cs.Updated = append(cs.Updated, p.Name)
+		default:
+			cs.Unchanged = append(cs.Unchanged, p.Name)
+		}
+	}
+	for _, p := range prev.Processes {
+		if !kept[p.Name] {
+			cs.Removed = append(cs.Removed, p.Name)
+		}
+	}
+
+	for _, names := range [][]string{cs.Added, cs.Updated, cs.Removed, cs.Unchanged} {
+		sort.Strings(names)
+	}
+	return cs
+}
+
+// sameProcess compares two programs by their YAML form, which treats
+// empty and missing values alike.
+func sameProcess(a, b ProcessConfig) bool {
+	a.Group, b.Group = "", ""
+	da, errA := yaml.Marshal(a)
+	db, errB := yaml.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(da, db)
+}
+
+// ParseProcess parses a single entry of the processes section, as sent to
+// the API when adding or updating a program at runtime. Like Load it
+// rejects unknown fields; the problems it and WithProcess report carry the
+// lines within data.
+func ParseProcess(data []byte) (ProcessConfig, error) {
+	v := &validator{}
+	var pc ProcessConfig
+	if !decodeStrict(data, &pc, v) {
+		return pc, v.err()
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err == nil && doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+		entries := indexEntries(&yaml.Node{Kind: yaml.SequenceNode, Content: doc.Content})
+		pc.lines = &entries[0]
+	}
+	if pc.Name == "" {
+		line := 0
+		if pc.lines != nil {
+			line = pc.lines.line
+		}
+		v.add(line, "process without a name")
+	}
+	return pc, v.err()
+}
+
+// HasProcess reports whether c defines the named program.
+func (c *Config) HasProcess(name string) bool {
+	return slices.ContainsFunc(c.Processes, func(p ProcessConfig) bool { return p.Name == name })
+}
+
+// WithProcess returns a copy of c in which pc is added, or replaces the
+// program of the same name. The copy is validated like a loaded file; c
+// itself is left untouched.
+func (c *Config) WithProcess(pc ProcessConfig) (*Config, error) {
+	cfg, err := c.clone()
+	if err != nil {
+		return nil, err
+	}
+	i := slices.IndexFunc(cfg.Processes, func(p ProcessConfig) bool { return p.Name == pc.Name })
+	if i < 0 {
+		i = len(cfg.Processes)
+		cfg.Processes = append(cfg.Processes, pc)
+	}
+	cfg.Processes[i] = pc
+	// Проблемы новой программы указывают на строки присланного фрагмента
+	if pc.lines != nil {
+		cfg.lines = &lineIndex{processes: make([]entryLines, i+1)}
+		cfg.lines.processes[i] = *pc.lines
+		cfg.Processes[i].lines = nil
+	}
+	if err := cfg.normalize(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalid, err)
+	}
+	return cfg, nil
+}
+
+// WithoutProcess returns a copy of c without the named program, which is
+// also dropped from its group. It fails if other programs depend on it.
+func (c *Config) WithoutProcess(name string) (*Config, error) {
+	cfg, err := c.clone()
+	if err != nil {
+		return nil, err
+	}
+	cfg.Processes = slices.DeleteFunc(cfg.Processes, func(p ProcessConfig) bool { return p.Name == name })
+	for i := range cfg.Groups {
+		cfg.Groups[i].Programs = slices.DeleteFunc(cfg.Groups[i].Programs, func(p string) bool { return p == name })
+	}
+	if err := cfg.normalize(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalid, err)
+	}
+	return cfg, nil
+}
+
+// clone makes a deep copy through the YAML form, so that normalizing the
+// copy cannot touch settings running processes still use.
+func (c *Config) clone() (*Config, error) {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	var cfg Config
+	v := &validator{}
+	if decodeStrict(data, &cfg, v); v.err() != nil {
+		return nil, v.err()
+	}
+	cfg.Path = c.Path
+	return &cfg, nil
+}
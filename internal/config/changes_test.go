@@ -0,0 +1,28 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseProcessRejectsUnknownFields(t *testing.T) {
+	_, err := ParseProcess([]byte("name: web\ncommand: sleep\nautorestrat: always\n"))
+	if !errors.Is(err, ErrInvalid) {
+		t.Fatalf("ParseProcess: %v, want %v", err, ErrInvalid)
+	}
+	if want := `line 3: unknown field "autorestrat"`; !strings.Contains(err.Error(), want) {
+		t.Errorf("ParseProcess: %v, want %q", err, want)
+	}
+}
+
+func TestWithProcessReportsLines(t *testing.T) {
+	pc, err := ParseProcess([]byte("name: web\ncommand: sleep\nautorestart: sometimes\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = (&Config{}).WithProcess(pc)
+	if want := `line 3: process web: unknown autorestart policy "sometimes"`; err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("WithProcess: %v, want %q", err, want)
+	}
+}
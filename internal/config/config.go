@@ -1,8 +1,13 @@
 package config
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
@@ -10,6 +15,139 @@ import (
 
 type Config struct {
 	Processes []ProcessConfig `yaml:"processes"`
+	Groups    []GroupConfig   `yaml:"groups,omitempty"`
+	Logging   LoggingConfig   `yaml:"logging,omitempty"`
+	// Notifications decide who is told when processes fail or recover
+	Notifications NotificationsConfig `yaml:"notifications,omitempty"`
+	Control       ControlConfig       `yaml:"control,omitempty"`
+	GRPC          GRPCConfig          `yaml:"grpc,omitempty"`
+	Auth          AuthConfig          `yaml:"auth,omitempty"`
+	Audit         AuditConfig         `yaml:"audit,omitempty"`
+	Metrics       MetricsConfig       `yaml:"metrics,omitempty"`
+
+	// Path is the file the config was loaded from, used for reloading.
+	Path string `yaml:"-"`
+
+	lines *lineIndex // где в файле заданы настройки, для сообщений
+}
+
+// GroupConfig assigns programs to a named group that can be controlled as
+// a whole, e.g. with "ingest:*".
+type GroupConfig struct {
+	Name     string   `yaml:"name"`
+	Programs []string `yaml:"programs"`
+}
+
+// RestartPolicy decides whether a process is restarted after it exits.
+type RestartPolicy string
+
+const (
+	// RestartAlways restarts the process whenever it exits.
+	RestartAlways RestartPolicy = "always"
+	// RestartOnFailure restarts the process if it exited with a non-zero
+	// code or was killed by a signal.
+	RestartOnFailure RestartPolicy = "on-failure"
+	// RestartUnexpected restarts the process if its exit code is not
+	// listed in ExitCodes.
+	RestartUnexpected RestartPolicy = "unexpected"
+	// RestartNever never restarts the process.
+	RestartNever RestartPolicy = "never"
+)
+
+// restartPolicyAliases maps accepted spellings onto the canonical policies.
+// Explicitly stopped processes are never restarted, so "unless-stopped"
+// behaves exactly like "always".
+var restartPolicyAliases = map[string]RestartPolicy{
+	"":               RestartNever,
+	"always":         RestartAlways,
+	"unless-stopped": RestartAlways,
+	"true":           RestartAlways,
+	"on-failure":     RestartOnFailure,
+	"unexpected":     RestartUnexpected,
+	"never":          RestartNever,
+	"false":          RestartNever,
+}
+
+// Defaults for RestartConfig fields left empty in the config file.
+const (
+	DefaultRestartInitialDelay = 1 * time.Second
+	DefaultRestartMaxDelay     = 30 * time.Second
+	DefaultRestartMultiplier   = 1.5
+	DefaultMaxRestarts         = 5
+	DefaultRestartResetAfter   = 60 * time.Second
+)
+
+// Defaults for the start-success settings, matching supervisord.
+const (
+	DefaultStartSecs    = 1
+	DefaultStartRetries = 3
+)
+
+// RestartConfig describes the backoff between restarts and how many
+// restarts are allowed before the process is given up on.
+type RestartConfig struct {
+	InitialDelay time.Duration `yaml:"initial_delay,omitempty"`
+	MaxDelay     time.Duration `yaml:"max_delay,omitempty"`
+	Multiplier   float64       `yaml:"multiplier,omitempty"`
+	// Jitter randomizes each delay by up to ±Jitter of its value (0..1).
+	Jitter float64 `yaml:"jitter,omitempty"`
+	// MaxRestarts is the budget within Window: 0 gives up on the first
+	// crash, a negative value means unlimited. See RestartLimit.
+	MaxRestarts *int `yaml:"max_restarts,omitempty"`
+	// Window is the sliding period restarts are counted in; zero counts
+	// every restart since the process was started.
+	Window time.Duration `yaml:"window,omitempty"`
+	// ResetAfter is how long the process must stay up for the restart
+	// counter and backoff to be reset.
+	ResetAfter time.Duration `yaml:"reset_after,omitempty"`
+}
+
+// Defaults for ProbeConfig fields left empty in the config file.
+const (
+	DefaultProbeInterval         = 10 * time.Second
+	DefaultProbeTimeout          = 1 * time.Second
+	DefaultProbeFailureThreshold = 3
+	DefaultProbeSuccessThreshold = 1
+)
+
+// ProbeConfig describes a liveness or readiness probe. Exactly one of HTTP,
+// TCP, Exec and GRPC has to be set.
+type ProbeConfig struct {
+	HTTP *HTTPProbe `yaml:"http,omitempty"`
+	TCP  *TCPProbe  `yaml:"tcp,omitempty"`
+	Exec *ExecProbe `yaml:"exec,omitempty"`
+	GRPC *GRPCProbe `yaml:"grpc,omitempty"`
+
+	InitialDelay     time.Duration `yaml:"initial_delay,omitempty"`
+	Interval         time.Duration `yaml:"interval,omitempty"`
+	Timeout          time.Duration `yaml:"timeout,omitempty"`
+	FailureThreshold int           `yaml:"failure_threshold,omitempty"`
+	SuccessThreshold int           `yaml:"success_threshold,omitempty"`
+}
+
+// HTTPProbe issues a GET request. With ExpectedStatus unset any 2xx or 3xx
+// response counts as success.
+type HTTPProbe struct {
+	URL            string            `yaml:"url"`
+	ExpectedStatus int               `yaml:"expected_status,omitempty"`
+	Headers        map[string]string `yaml:"headers,omitempty"`
+}
+
+// TCPProbe succeeds when a connection to Address can be established.
+type TCPProbe struct {
+	Address string `yaml:"address"`
+}
+
+// ExecProbe succeeds when the command exits with code 0.
+type ExecProbe struct {
+	Command string   `yaml:"command"`
+	Args    []string `yaml:"args,omitempty"`
+}
+
+// GRPCProbe calls grpc.health.v1.Health/Check and expects SERVING.
+type GRPCProbe struct {
+	Address string `yaml:"address"`
+	Service string `yaml:"service,omitempty"`
 }
 
 type ProcessConfig struct {
@@ -19,37 +157,351 @@ type ProcessConfig struct {
 	Directory   string            `yaml:"directory,omitempty"`
 	Environment map[string]string `yaml:"env,omitempty"`
 	Autostart   bool              `yaml:"autostart"`
-	Autorestart string            `yaml:"autorestart"`
-	StopSignal  string            `yaml:"stop_signal,omitempty"`
-	StopWait    time.Duration     `yaml:"stop_wait,omitempty"`
+	Autorestart RestartPolicy     `yaml:"autorestart"`
+	ExitCodes   []int             `yaml:"exitcodes,omitempty"`
+	Restart     RestartConfig     `yaml:"restart,omitempty"`
+	// StartSecs is how long the process has to stay up to count as
+	// started; StartRetries is how many failed starts in a row are retried.
+	StartSecs    *int         `yaml:"start_secs,omitempty"`
+	StartRetries *int         `yaml:"start_retries,omitempty"`
+	Liveness     *ProbeConfig `yaml:"liveness,omitempty"`
+	Readiness    *ProbeConfig `yaml:"readiness,omitempty"`
+	// DependsOn lists processes that StartAll brings up first; Priority
+	// orders independent processes, lower values start first.
+	DependsOn []Dependency `yaml:"depends_on,omitempty"`
+	Priority  int          `yaml:"priority,omitempty"`
+	// NumProcs runs that many instances of the program, numbered from
+	// NumProcsStart. See Instances.
+	NumProcs      int           `yaml:"numprocs,omitempty"`
+	NumProcsStart int           `yaml:"numprocs_start,omitempty"`
+	StopSignal    string        `yaml:"stop_signal,omitempty"`
+	StopWait      time.Duration `yaml:"stop_wait,omitempty"`
+	// StdoutLogfile and StderrLogfile persist the child's output; with
+	// RedirectStderr stderr is written to the stdout log as well.
+	StdoutLogfile  string          `yaml:"stdout_logfile,omitempty"`
+	StderrLogfile  string          `yaml:"stderr_logfile,omitempty"`
+	RedirectStderr bool            `yaml:"redirect_stderr,omitempty"`
+	LogRotate      LogRotateConfig `yaml:"log_rotate,omitempty"`
+	// StdoutEvents and StderrEvents emit PROCESS_LOG events for every
+	// output line, for event listeners to consume.
+	StdoutEvents  bool                 `yaml:"stdout_events_enabled,omitempty"`
+	StderrEvents  bool                 `yaml:"stderr_events_enabled,omitempty"`
+	EventListener *EventListenerConfig `yaml:"eventlistener,omitempty"`
+
+	// Program and Instance are filled in by Instances, Group by Load.
+	Program  string `yaml:"-"`
+	Instance int    `yaml:"-"`
+	Group    string `yaml:"-"`
+
+	lines *entryLines // строки настроек, если задан через ParseProcess
 }
 
+// Load reads and validates a config file. Unknown fields are rejected, and
+// all problems found are reported together as a *ValidationError.
 func Load(filename string) (*Config, error) {
 	data, err := os.ReadFile(filename)
 	if err != nil {
 		return nil, err
 	}
 
+	v := &validator{file: filename}
 	var cfg Config
-	if err := yaml.Unmarshal(data, &cfg); err != nil {
+	if !decodeStrict(data, &cfg, v) {
+		return nil, v.err()
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err == nil {
+		cfg.lines = indexLines(&doc)
+	}
+	if abs, err := filepath.Abs(filename); err == nil {
+		cfg.Path = abs
+	} else {
+		cfg.Path = filename
+	}
+	cfg.validate(v)
+	if err := v.err(); err != nil {
 		return nil, err
 	}
+	return &cfg, nil
+}
 
+// decodeStrict decodes data into out, recording unknown fields and type
+// errors in v. It returns false if data is not valid YAML at all.
+func decodeStrict(data []byte, out any, v *validator) bool {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	err := dec.Decode(out)
+	if err == nil || err == io.EOF {
+		return true
+	}
+	var te *yaml.TypeError
+	if !errors.As(err, &te) {
+		// Синтаксическая ошибка: дальше проверять нечего
+		v.addYAML(err.Error())
+		return false
+	}
+	for _, msg := range te.Errors {
+		v.addYAML(msg)
+	}
+	return true
+}
+
+// normalize validates cfg and fills in defaults. Applying it to an already
+// normalized config again changes nothing.
+func (cfg *Config) normalize() error {
+	v := &validator{}
+	cfg.validate(v)
+	return v.err()
+}
+
+// validate fills in defaults and records every problem with cfg in v.
+func (cfg *Config) validate(v *validator) {
+	ix := cfg.lines
+	names := make(map[string]bool, len(cfg.Processes))
 	for i := range cfg.Processes {
-		if cfg.Processes[i].Directory != "" {
-			if abs, err := filepath.Abs(cfg.Processes[i].Directory); err == nil {
-				cfg.Processes[i].Directory = abs
+		pc := &cfg.Processes[i]
+		switch {
+		case pc.Name == "":
+			v.add(ix.process(i, ""), "process #%d has no name", i+1)
+		case names[pc.Name]:
+			v.add(ix.process(i, "name"), "duplicate process %s", pc.Name)
+		case strings.ContainsAny(pc.Name, ":* \t"):
+			v.add(ix.process(i, "name"), "process %s: name must not contain ':', '*' or spaces", pc.Name)
+		}
+		names[pc.Name] = true
+		if pc.Command == "" {
+			v.add(ix.process(i, "command"), "process %s: command is required", pc.Name)
+		}
+
+		if pc.Directory != "" {
+			if abs, err := filepath.Abs(pc.Directory); err == nil {
+				pc.Directory = abs
 			}
 		}
+		for _, path := range []*string{&pc.StdoutLogfile, &pc.StderrLogfile} {
+			if *path != "" {
+				if abs, err := filepath.Abs(*path); err == nil {
+					*path = abs
+				}
+			}
+		}
+		if pc.RedirectStderr && pc.StderrLogfile != "" {
+			v.add(ix.process(i, "stderr_logfile"), "process %s: stderr_logfile cannot be used with redirect_stderr", pc.Name)
+		}
+		applyLogDefaults(&pc.LogRotate)
+
+		if err := applyEventListenerDefaults(pc); err != nil {
+			v.add(ix.process(i, "eventlistener"), "process %s: %v", pc.Name, err)
+		}
+
+		if policy, ok := restartPolicyAliases[string(pc.Autorestart)]; ok {
+			pc.Autorestart = policy
+		} else {
+			v.add(ix.process(i, "autorestart"), "process %s: unknown autorestart policy %q", pc.Name, pc.Autorestart)
+		}
+
+		if len(pc.ExitCodes) == 0 {
+			pc.ExitCodes = []int{0}
+		}
+
+		applyRestartDefaults(&pc.Restart)
+
+		for _, probe := range []struct {
+			kind string
+			cfg  *ProbeConfig
+		}{{"liveness", pc.Liveness}, {"readiness", pc.Readiness}} {
+			if probe.cfg == nil {
+				continue
+			}
+			if err := applyProbeDefaults(probe.cfg); err != nil {
+				v.add(ix.process(i, probe.kind), "process %s: %s probe: %v", pc.Name, probe.kind, err)
+			}
+		}
+
+		if err := normalizeDependencies(pc); err != nil {
+			v.add(ix.process(i, "depends_on"), "process %s: %v", pc.Name, err)
+		}
 
-		if cfg.Processes[i].StopSignal == "" {
-			cfg.Processes[i].StopSignal = "SIGTERM"
+		if pc.StopSignal == "" {
+			pc.StopSignal = "SIGTERM"
 		}
 
-		if cfg.Processes[i].StopWait == 0 {
-			cfg.Processes[i].StopWait = 10 * time.Second
+		if pc.StopWait == 0 {
+			pc.StopWait = 10 * time.Second
 		}
 	}
 
-	return &cfg, nil
+	assignGroups(cfg, v)
+
+	if err := applyLoggingDefaults(&cfg.Logging); err != nil {
+		v.addErr(ix.section("logging"), err)
+	}
+
+	if err := applyNotificationDefaults(&cfg.Notifications); err != nil {
+		v.addErr(ix.section("notifications"), err)
+	}
+
+	if err := applyControlDefaults(&cfg.Control, cfg.Path); err != nil {
+		v.addErr(ix.section("control"), err)
+	}
+
+	if err := applyGRPCDefaults(&cfg.GRPC); err != nil {
+		v.addErr(ix.section("grpc"), err)
+	}
+
+	if err := applyAuthDefaults(&cfg.Auth); err != nil {
+		v.addErr(ix.section("auth"), err)
+	}
+
+	applyAuditDefaults(&cfg.Audit)
+
+	// Зависимости проверяем на уровне экземпляров: depends_on может
+	// ссылаться и на программу, и на отдельный экземпляр
+	var instances []ProcessConfig
+	known := make(map[string]bool)
+	for i, p := range cfg.Processes {
+		inst, err := p.Instances()
+		if err != nil {
+			v.add(ix.process(i, "numprocs"), "%v", err)
+			continue
+		}
+		// Экземпляры одной программы часто дают одну и ту же проблему
+		seen := make(map[string]bool)
+		for _, in := range inst {
+			known[in.Name] = true
+			dir, command := checkHost(in)
+			for _, check := range [][2]string{{"directory", dir}, {"command", command}} {
+				key, problem := check[0], check[1]
+				if problem != "" && !seen[problem] {
+					seen[problem] = true
+					v.add(ix.process(i, key), "process %s: %s", p.Name, problem)
+				}
+			}
+		}
+		known[p.Name] = true
+		instances = append(instances, inst...)
+	}
+	resolved := true
+	for i, p := range cfg.Processes {
+		for _, dep := range p.DependsOn {
+			if !known[dep.Name] {
+				resolved = false
+				v.add(ix.process(i, "depends_on"), "process %s depends on unknown process %s", p.Name, dep.Name)
+			}
+		}
+	}
+	// Циклы ищем, даже если нашлись другие проблемы: нужны все за один раз
+	if resolved {
+		if _, err := StartOrder(instances); err != nil {
+			v.add(ix.section("processes"), "%v", err)
+		}
+	}
+}
+
+// StartGrace returns StartSecs as a duration.
+func (c ProcessConfig) StartGrace() time.Duration {
+	if c.StartSecs == nil {
+		return DefaultStartSecs * time.Second
+	}
+	return time.Duration(*c.StartSecs) * time.Second
+}
+
+// RestartLimit returns MaxRestarts or its default.
+func (rc RestartConfig) RestartLimit() int {
+	if rc.MaxRestarts == nil {
+		return DefaultMaxRestarts
+	}
+	return *rc.MaxRestarts
+}
+
+// MaxStartRetries returns StartRetries or its default.
+func (c ProcessConfig) MaxStartRetries() int {
+	if c.StartRetries == nil {
+		return DefaultStartRetries
+	}
+	return *c.StartRetries
+}
+
+func applyRestartDefaults(rc *RestartConfig) {
+	if rc.InitialDelay == 0 {
+		rc.InitialDelay = DefaultRestartInitialDelay
+	}
+	if rc.MaxDelay == 0 {
+		rc.MaxDelay = DefaultRestartMaxDelay
+	}
+	if rc.MaxDelay < rc.InitialDelay {
+		rc.MaxDelay = rc.InitialDelay
+	}
+	if rc.Multiplier == 0 {
+		rc.Multiplier = DefaultRestartMultiplier
+	}
+	if rc.ResetAfter == 0 {
+		rc.ResetAfter = DefaultRestartResetAfter
+	}
+}
+
+func applyProbeDefaults(pc *ProbeConfig) error {
+	kinds := 0
+	for _, set := range []bool{pc.HTTP != nil, pc.TCP != nil, pc.Exec != nil, pc.GRPC != nil} {
+		if set {
+			kinds++
+		}
+	}
+	if kinds != 1 {
+		return fmt.Errorf("exactly one of http, tcp, exec or grpc must be set")
+	}
+
+	if pc.Interval == 0 {
+		pc.Interval = DefaultProbeInterval
+	}
+	if pc.Timeout == 0 {
+		pc.Timeout = DefaultProbeTimeout
+	}
+	if pc.FailureThreshold == 0 {
+		pc.FailureThreshold = DefaultProbeFailureThreshold
+	}
+	if pc.SuccessThreshold == 0 {
+		pc.SuccessThreshold = DefaultProbeSuccessThreshold
+	}
+	return nil
+}
+
+// assignGroups validates the groups section and records each program's
+// group in its ProcessConfig.
+func assignGroups(cfg *Config, v *validator) {
+	index := make(map[string]int, len(cfg.Processes))
+	for i, p := range cfg.Processes {
+		index[p.Name] = i
+		cfg.Processes[i].Group = ""
+	}
+
+	seen := make(map[string]bool, len(cfg.Groups))
+	for gi, g := range cfg.Groups {
+		if g.Name == "" {
+			v.add(cfg.lines.group(gi, ""), "group without a name")
+			continue
+		}
+		if seen[g.Name] {
+			v.add(cfg.lines.group(gi, "name"), "duplicate group %s", g.Name)
+			continue
+		}
+		if _, clash := index[g.Name]; clash {
+			v.add(cfg.lines.group(gi, "name"), "group %s has the same name as a process", g.Name)
+		}
+		seen[g.Name] = true
+
+		for _, prog := range g.Programs {
+			i, ok := index[prog]
+			if !ok {
+				v.add(cfg.lines.group(gi, "programs"), "group %s: unknown program %s", g.Name, prog)
+				continue
+			}
+			if other := cfg.Processes[i].Group; other != "" && other != g.Name {
+				v.add(cfg.lines.group(gi, "programs"), "program %s is in both groups %s and %s", prog, other, g.Name)
+				continue
+			}
+			cfg.Processes[i].Group = g.Name
+		}
+	}
 }
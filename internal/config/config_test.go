@@ -0,0 +1,37 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func loadString(t *testing.T, data string) *Config {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "gosv.yaml")
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+func TestRestartLimit(t *testing.T) {
+	for _, tt := range []struct {
+		restart string
+		want    int
+	}{
+		{"", DefaultMaxRestarts},
+		{"restart: {max_restarts: 0}", 0},
+		{"restart: {max_restarts: 3}", 3},
+		{"restart: {max_restarts: -1}", -1},
+	} {
+		cfg := loadString(t, "processes:\n  - name: web\n    command: sleep\n    "+tt.restart+"\n")
+		if got := cfg.Processes[0].Restart.RestartLimit(); got != tt.want {
+			t.Errorf("%q: RestartLimit() = %d, want %d", tt.restart, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultControlSocketMode restricts the control socket to its owner.
+const DefaultControlSocketMode = "0600"
+
+// ControlConfig configures the Unix domain socket the daemon serves its
+// API on, and that the -start, -stop, -restart, -status and -reload flags
+// connect to.
+type ControlConfig struct {
+	// Socket is the socket path, relative to the config file. Without it
+	// the daemon listens on gosv-<config name>-<hash>.sock in
+	// $XDG_RUNTIME_DIR, or on <config name>.sock next to the config file
+	// when that is not set. gosv-client -c finds the same socket.
+	Socket string `yaml:"socket,omitempty"`
+	// Mode holds the socket's file permissions as an octal string such as
+	// "0660".
+	Mode string `yaml:"mode,omitempty"`
+}
+
+// DefaultControlSocket is used when the config file names no socket. The
+// name comes from the absolute config path, so daemons of different
+// configs do not collide: in $XDG_RUNTIME_DIR, which only the user can
+// write to, it carries a hash of the path; next to the config file, the
+// fallback, the name of the file is enough. A shared directory like /tmp
+// would let another user create the socket first and answer in the
+// daemon's place.
+func DefaultControlSocket(configPath string) string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return runtimeSocket(dir, configPath)
+	}
+	return configSocket(configPath)
+}
+
+func runtimeSocket(dir, configPath string) string {
+	if abs, err := filepath.Abs(configPath); err == nil {
+		configPath = abs
+	}
+	sum := sha256.Sum256([]byte(configPath))
+	return filepath.Join(dir, fmt.Sprintf("gosv-%s-%x.sock", configName(configPath), sum[:4]))
+}
+
+func configSocket(configPath string) string {
+	if abs, err := filepath.Abs(configPath); err == nil {
+		configPath = abs
+	}
+	return filepath.Join(filepath.Dir(configPath), configName(configPath)+".sock")
+}
+
+func configName(configPath string) string {
+	return strings.TrimSuffix(filepath.Base(configPath), filepath.Ext(configPath))
+}
+
+// ControlSocket returns the socket a daemon started with the config file
+// at path listens on. Only the control section is read, so clients can
+// find the daemon without a config that would pass Load. Without a socket
+// in the file, a daemon started without $XDG_RUNTIME_DIR, e.g. by systemd
+// or sudo, is found next to the config file as well.
+func ControlSocket(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	var cfg struct {
+		Control ControlConfig `yaml:"control"`
+	}
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return "", fmt.Errorf("%s: %w", path, err)
+	}
+	if cfg.Control.Socket == "" {
+		if _, err := os.Stat(DefaultControlSocket(path)); err != nil {
+			if _, err := os.Stat(configSocket(path)); err == nil {
+				return configSocket(path), nil
+			}
+		}
+	}
+	if err := applyControlDefaults(&cfg.Control, path); err != nil {
+		return "", fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg.Control.Socket, nil
+}
+
+// FileMode parses Mode; Load has validated it already.
+func (c ControlConfig) FileMode() os.FileMode {
+	mode, err := strconv.ParseUint(c.Mode, 8, 32)
+	if err != nil {
+		return 0600
+	}
+	return os.FileMode(mode) & os.ModePerm
+}
+
+func applyControlDefaults(c *ControlConfig, configPath string) error {
+	switch {
+	case c.Socket == "":
+		c.Socket = DefaultControlSocket(configPath)
+	case !filepath.IsAbs(c.Socket):
+		// Клиенты из другого каталога должны найти тот же сокет
+		if abs, err := filepath.Abs(filepath.Join(filepath.Dir(configPath), c.Socket)); err == nil {
+			c.Socket = abs
+		}
+	}
+	if c.Mode == "" {
+		c.Mode = DefaultControlSocketMode
+	}
+	mode, err := strconv.ParseUint(c.Mode, 8, 32)
+	if err != nil || mode&^uint64(os.ModePerm) != 0 {
+		return fmt.Errorf("control: invalid socket mode %q", c.Mode)
+	}
+	return nil
+}
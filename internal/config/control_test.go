@@ -0,0 +1,87 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDefaultControlSocket(t *testing.T) {
+	dir := t.TempDir()
+	web, jobs := filepath.Join(dir, "web", "gosv.yaml"), filepath.Join(dir, "jobs", "gosv.yaml")
+
+	runtimeDir := t.TempDir()
+	t.Setenv("XDG_RUNTIME_DIR", runtimeDir)
+	a, b := DefaultControlSocket(web), DefaultControlSocket(jobs)
+	if filepath.Dir(a) != runtimeDir || !strings.HasPrefix(filepath.Base(a), "gosv-gosv-") {
+		t.Errorf("socket %s, want gosv-gosv-<hash>.sock in the runtime dir", a)
+	}
+	if a == b {
+		t.Errorf("configs in different directories share the socket %s", a)
+	}
+
+	// Относительный путь к тому же файлу даёт тот же сокет
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rel, err := filepath.Rel(wd, web)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := DefaultControlSocket(rel); got != a {
+		t.Errorf("relative config path gives %s, want %s", got, a)
+	}
+
+	t.Setenv("XDG_RUNTIME_DIR", "")
+	if got, want := DefaultControlSocket(web), filepath.Join(dir, "web", "gosv.sock"); got != want {
+		t.Errorf("without a runtime dir: %s, want %s", got, want)
+	}
+}
+
+// A daemon started without $XDG_RUNTIME_DIR is found by a client that has
+// it set.
+func TestControlSocketBesideConfig(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+	path := filepath.Join(t.TempDir(), "gosv.yaml")
+	if err := os.WriteFile(path, []byte("processes: []\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := ControlSocket(path); err != nil || got != DefaultControlSocket(path) {
+		t.Errorf("without any socket: %s, %v; want %s", got, err, DefaultControlSocket(path))
+	}
+
+	beside := filepath.Join(filepath.Dir(path), "gosv.sock")
+	if err := os.WriteFile(beside, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := ControlSocket(path); err != nil || got != beside {
+		t.Errorf("with a socket next to the config: %s, %v; want %s", got, err, beside)
+	}
+}
+
+// ControlSocket, used by clients, agrees with Load, used by the daemon.
+func TestControlSocketMatchesLoad(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+	for _, control := range []string{"", "control: {socket: run/gosv.sock}\n", "control: {socket: " + filepath.Join(t.TempDir(), "gosv.sock") + "}\n"} {
+		path := filepath.Join(t.TempDir(), "gosv.yaml")
+		if err := os.WriteFile(path, []byte(control+"processes:\n  - name: web\n    command: sleep\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		cfg, err := Load(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := ControlSocket(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != cfg.Control.Socket {
+			t.Errorf("%q: ControlSocket = %s, Load has %s", control, got, cfg.Control.Socket)
+		}
+		if control == "control: {socket: run/gosv.sock}\n" && got != filepath.Join(filepath.Dir(path), "run", "gosv.sock") {
+			t.Errorf("relative socket %s is not next to the config file", got)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DependencyCondition is the state a dependency has to reach before the
+// dependent process is started.
+type DependencyCondition string
+
+const (
+	ConditionStarted DependencyCondition = "started"
+	ConditionHealthy DependencyCondition = "healthy"
+	// ConditionCompleted waits until the dependency exits with code 0, as
+	// a migration does. Such a dependency may exit within start_secs
+	// without that counting as a failed start.
+	ConditionCompleted DependencyCondition = "completed_successfully"
+)
+
+// Dependency is an entry of depends_on. It can be written either as a
+// plain process name or as a mapping with a condition.
+type Dependency struct {
+	Name      string              `yaml:"name"`
+	Condition DependencyCondition `yaml:"condition,omitempty"`
+}
+
+func (d *Dependency) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		d.Name = value.Value
+		return nil
+	}
+	// value.Decode не наследует KnownFields, проверяем поля сами
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		if key := value.Content[i]; key.Value != "name" && key.Value != "condition" {
+			return &yaml.TypeError{Errors: []string{
+				fmt.Sprintf("line %d: field %s not found in type config.Dependency", key.Line, key.Value),
+			}}
+		}
+	}
+	type plain Dependency
+	return value.Decode((*plain)(d))
+}
+
+func normalizeDependencies(pc *ProcessConfig) error {
+	for i := range pc.DependsOn {
+		dep := &pc.DependsOn[i]
+		switch dep.Condition {
+		case "":
+			dep.Condition = ConditionStarted
+		case ConditionStarted, ConditionHealthy, ConditionCompleted:
+		default:
+			return fmt.Errorf("dependency %s: unknown condition %q", dep.Name, dep.Condition)
+		}
+	}
+	return nil
+}
+
+// StartOrder sorts process names so that every process comes after the
+// processes it depends on. Processes that do not depend on each other are
+// ordered by Priority (lower first), then by name.
+//
+// procs may contain instances produced by Instances; a dependency on a
+// program name then refers to all of its instances.
+func StartOrder(procs []ProcessConfig) ([]string, error) {
+	byName := make(map[string]ProcessConfig, len(procs))
+	for _, p := range procs {
+		byName[p.Name] = p
+	}
+	deps := dependencyNames(procs)
+
+	indegree := make(map[string]int, len(procs))
+	dependents := make(map[string][]string)
+	for _, p := range procs {
+		for _, dep := range p.DependsOn {
+			if len(deps[dep.Name]) == 0 {
+				return nil, fmt.Errorf("process %s depends on unknown process %s", p.Name, dep.Name)
+			}
+		}
+		for _, name := range resolveDependencies(p, deps) {
+			indegree[p.Name]++
+			dependents[name] = append(dependents[name], p.Name)
+		}
+	}
+
+	less := func(a, b string) bool {
+		if byName[a].Priority != byName[b].Priority {
+			return byName[a].Priority < byName[b].Priority
+		}
+		return a < b
+	}
+
+	var ready []string
+	for _, p := range procs {
+		if indegree[p.Name] == 0 {
+			ready = append(ready, p.Name)
+		}
+	}
+
+	order := make([]string, 0, len(procs))
+	for len(ready) > 0 {
+		sort.Slice(ready, func(i, j int) bool { return less(ready[i], ready[j]) })
+		name := ready[0]
+		ready = ready[1:]
+		order = append(order, name)
+
+		for _, d := range dependents[name] {
+			indegree[d]--
+			if indegree[d] == 0 {
+				ready = append(ready, d)
+			}
+		}
+	}
+
+	if len(order) < len(byName) {
+		return nil, fmt.Errorf("dependency cycle: %s", strings.Join(findCycle(procs), " -> "))
+	}
+	return order, nil
+}
+
+// dependencyNames maps every name a dependency may refer to, process or
+// program, onto the process names it stands for.
+func dependencyNames(procs []ProcessConfig) map[string][]string {
+	names := make(map[string][]string, len(procs))
+	for _, p := range procs {
+		names[p.Name] = append(names[p.Name], p.Name)
+		if p.Program != "" && p.Program != p.Name {
+			names[p.Program] = append(names[p.Program], p.Name)
+		}
+	}
+	return names
+}
+
+// resolveDependencies returns the process names p depends on.
+func resolveDependencies(p ProcessConfig, names map[string][]string) []string {
+	var out []string
+	for _, dep := range p.DependsOn {
+		out = append(out, names[dep.Name]...)
+	}
+	return out
+}
+
+// findCycle returns one dependency cycle, starting and ending with the
+// same process name.
+func findCycle(procs []ProcessConfig) []string {
+	names := dependencyNames(procs)
+	deps := make(map[string][]string, len(procs))
+	for _, p := range procs {
+		deps[p.Name] = resolveDependencies(p, names)
+	}
+
+	const (
+		unvisited = iota
+		inProgress
+		visited
+	)
+	state := make(map[string]int)
+	var stack []string
+
+	var visit func(name string) []string
+	visit = func(name string) []string {
+		state[name] = inProgress
+		stack = append(stack, name)
+		for _, dep := range deps[name] {
+			switch state[dep] {
+			case inProgress:
+				for i, n := range stack {
+					if n == dep {
+						return append(append([]string{}, stack[i:]...), dep)
+					}
+				}
+			case unvisited:
+				if cycle := visit(dep); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[name] = visited
+		return nil
+	}
+
+	for _, p := range procs {
+		if state[p.Name] == unvisited {
+			if cycle := visit(p.Name); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func deps(names ...string) []Dependency {
+	var out []Dependency
+	for _, n := range names {
+		out = append(out, Dependency{Name: n, Condition: ConditionStarted})
+	}
+	return out
+}
+
+func TestStartOrder(t *testing.T) {
+	procs := []ProcessConfig{
+		{Name: "api", DependsOn: deps("db", "migrate")},
+		{Name: "migrate", DependsOn: deps("db")},
+		{Name: "db"},
+		{Name: "cache", Priority: -1},
+		// Зависимость от программы означает все её экземпляры
+		{Name: "worker:00", Program: "worker"},
+		{Name: "worker:01", Program: "worker"},
+		{Name: "proxy", DependsOn: deps("worker", "api")},
+	}
+	got, err := StartOrder(procs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"cache", "db", "migrate", "api", "worker:00", "worker:01", "proxy"}
+	if !slices.Equal(got, want) {
+		t.Errorf("StartOrder = %v, want %v", got, want)
+	}
+}
+
+func TestStartOrderErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		procs []ProcessConfig
+		want  string
+	}{
+		{
+			name:  "self",
+			procs: []ProcessConfig{{Name: "a", DependsOn: deps("a")}},
+			want:  "dependency cycle: a -> a",
+		},
+		{
+			name: "three processes",
+			procs: []ProcessConfig{
+				{Name: "a", DependsOn: deps("b")},
+				{Name: "b", DependsOn: deps("c")},
+				{Name: "c", DependsOn: deps("a")},
+				{Name: "d"},
+			},
+			want: "dependency cycle: a -> b -> c -> a",
+		},
+		{
+			name: "through a program",
+			procs: []ProcessConfig{
+				{Name: "w:00", Program: "w", DependsOn: deps("api")},
+				{Name: "w:01", Program: "w"},
+				{Name: "api", DependsOn: deps("w")},
+			},
+			want: "dependency cycle: w:00 -> api -> w:00",
+		},
+		{
+			name:  "unknown dependency",
+			procs: []ProcessConfig{{Name: "a", DependsOn: deps("nope")}},
+			want:  "process a depends on unknown process nope",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := StartOrder(tt.procs)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("StartOrder: %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/kolkov/gosv/internal/eventlistener"
+)
+
+// EventListenerConfig turns a program into a supervisord-style event
+// listener: instead of regular output, it reads events on stdin and
+// acknowledges them on stdout. All instances of the program form one pool
+// that shares the event buffer.
+type EventListenerConfig struct {
+	// Events lists the subscribed event types, e.g. PROCESS_STATE or
+	// TICK_60.
+	Events []string `yaml:"events"`
+	// BufferSize is how many events are kept while no listener is ready;
+	// beyond that the oldest event is discarded.
+	BufferSize int `yaml:"buffer_size,omitempty"`
+}
+
+func applyEventListenerDefaults(pc *ProcessConfig) error {
+	el := pc.EventListener
+	if el == nil {
+		return nil
+	}
+	if len(el.Events) == 0 {
+		return errors.New("eventlistener: no events")
+	}
+	for _, name := range el.Events {
+		if !eventlistener.ValidEventType(name) {
+			return fmt.Errorf("eventlistener: unknown event type %q", name)
+		}
+	}
+	if el.BufferSize == 0 {
+		el.BufferSize = eventlistener.DefaultBufferSize
+	}
+	if el.BufferSize < 0 {
+		return fmt.Errorf("eventlistener: invalid buffer_size %d", el.BufferSize)
+	}
+	// stdout занят протоколом
+	if pc.RedirectStderr {
+		return errors.New("eventlistener: redirect_stderr would corrupt the protocol")
+	}
+	if pc.StdoutLogfile != "" {
+		return errors.New("eventlistener: stdout_logfile cannot be used, stdout carries the protocol")
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// GRPCConfig configures the TCP listener of the API for remote clients.
+// Local clients use the control socket instead.
+type GRPCConfig struct {
+	// Address is the host:port to listen on, e.g. "127.0.0.1:50051"; the
+	// listener is disabled when it is empty.
+	Address string     `yaml:"address,omitempty"`
+	TLS     *TLSConfig `yaml:"tls,omitempty"`
+}
+
+// TLSConfig holds PEM files for the server side of TLS. They are read
+// again on SIGHUP, so renewed certificates apply without a restart.
+type TLSConfig struct {
+	Cert string `yaml:"cert"`
+	Key  string `yaml:"key"`
+	// ClientCA enables mutual TLS: only clients presenting a certificate
+	// signed by this CA are accepted.
+	ClientCA string `yaml:"client_ca,omitempty"`
+}
+
+func applyGRPCDefaults(g *GRPCConfig) error {
+	t := g.TLS
+	if t == nil {
+		return nil
+	}
+	if t.Cert == "" || t.Key == "" {
+		return errors.New("grpc: tls needs both cert and key")
+	}
+	var errs []error
+	for _, path := range []*string{&t.Cert, &t.Key, &t.ClientCA} {
+		if *path == "" {
+			continue
+		}
+		if abs, err := filepath.Abs(*path); err == nil {
+			*path = abs
+		}
+		if _, err := os.Stat(*path); err != nil {
+			errs = append(errs, fmt.Errorf("grpc: tls: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// instanceVars are the values available to templates in instance configs.
+type instanceVars struct {
+	ProgramName string
+	ProcessName string
+	ProcessNum  int
+}
+
+func (v instanceVars) expand(s string) (string, error) {
+	if !strings.Contains(s, "{{") {
+		return s, nil
+	}
+	tmpl, err := template.New("").Option("missingkey=error").Parse(s)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, v); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// Instances expands NumProcs into one config per instance. With more than
+// one instance the names become "program:NN". {{.ProgramName}},
+// {{.ProcessName}} and {{.ProcessNum}} are expanded in args, env values,
+// the directory and the log file paths, and the same values are exported
+// to the child as GOSV_PROGRAM_NAME, GOSV_PROCESS_NAME and GOSV_PROCESS_NUM.
+func (c ProcessConfig) Instances() ([]ProcessConfig, error) {
+	n := c.NumProcs
+	if n < 0 {
+		return nil, fmt.Errorf("process %s: numprocs must not be negative", c.Name)
+	}
+	if n == 0 {
+		n = 1
+	}
+
+	width := len(strconv.Itoa(c.NumProcsStart + n - 1))
+	if width < 2 {
+		width = 2
+	}
+
+	instances := make([]ProcessConfig, 0, n)
+	for i := 0; i < n; i++ {
+		num := c.NumProcsStart + i
+		inst := c
+		inst.Program = c.Name
+		inst.Instance = num
+		if n > 1 {
+			inst.Name = fmt.Sprintf("%s:%0*d", c.Name, width, num)
+		}
+
+		vars := instanceVars{ProgramName: c.Name, ProcessName: inst.Name, ProcessNum: num}
+		var err error
+
+		inst.Args = make([]string, len(c.Args))
+		for j, arg := range c.Args {
+			if inst.Args[j], err = vars.expand(arg); err != nil {
+				return nil, fmt.Errorf("process %s: args: %w", inst.Name, err)
+			}
+		}
+
+		inst.Environment = make(map[string]string, len(c.Environment)+3)
+		// По порядку ключей, чтобы ошибка не зависела от обхода map
+		for _, k := range slices.Sorted(maps.Keys(c.Environment)) {
+			if inst.Environment[k], err = vars.expand(c.Environment[k]); err != nil {
+				return nil, fmt.Errorf("process %s: env %s: %w", inst.Name, k, err)
+			}
+		}
+		inst.Environment["GOSV_PROGRAM_NAME"] = c.Name
+		inst.Environment["GOSV_PROCESS_NAME"] = inst.Name
+		inst.Environment["GOSV_PROCESS_NUM"] = strconv.Itoa(num)
+
+		if inst.Directory, err = vars.expand(c.Directory); err != nil {
+			return nil, fmt.Errorf("process %s: directory: %w", inst.Name, err)
+		}
+		if inst.StdoutLogfile, err = vars.expand(c.StdoutLogfile); err != nil {
+			return nil, fmt.Errorf("process %s: stdout_logfile: %w", inst.Name, err)
+		}
+		if inst.StderrLogfile, err = vars.expand(c.StderrLogfile); err != nil {
+			return nil, fmt.Errorf("process %s: stderr_logfile: %w", inst.Name, err)
+		}
+
+		instances = append(instances, inst)
+	}
+	return instances, nil
+}
@@ -0,0 +1,162 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/kolkov/gosv/internal/logfile"
+	"gopkg.in/yaml.v3"
+)
+
+// Defaults for LogRotateConfig fields left empty in the config file,
+// matching supervisord.
+const (
+	DefaultLogMaxSize    ByteSize = 50 * MB
+	DefaultLogMaxBackups          = 10
+)
+
+// DefaultLogBufferSize is how many records the supervisor keeps in memory
+// per process.
+const DefaultLogBufferSize = 1000
+
+// LoggingConfig configures the supervisor's own log, which carries both
+// supervisor messages and process output.
+type LoggingConfig struct {
+	// Level is the minimum level written to File: debug, info, warn or
+	// error.
+	Level string `yaml:"level,omitempty"`
+	// File is written in Format, either text or json (one object per line).
+	File   string          `yaml:"file,omitempty"`
+	Format string          `yaml:"format,omitempty"`
+	Rotate LogRotateConfig `yaml:"rotate,omitempty"`
+	// BufferSize is how many recent records are kept per process for the
+	// TUI and API.
+	BufferSize int `yaml:"buffer_size,omitempty"`
+}
+
+// LogRotateConfig controls rotation of a process's log files.
+type LogRotateConfig struct {
+	// MaxSize is the size at which a log file is rotated; a negative value
+	// disables rotation.
+	MaxSize ByteSize `yaml:"max_size,omitempty"`
+	// MaxBackups is how many rotated files are kept; zero keeps none and
+	// truncates the log instead.
+	MaxBackups *int `yaml:"max_backups,omitempty"`
+	// Compress gzips rotated files.
+	Compress bool `yaml:"compress,omitempty"`
+}
+
+// Options converts the settings for the logfile package.
+func (c LogRotateConfig) Options() logfile.Options {
+	return logfile.Options{
+		MaxSize:    int64(c.MaxSize),
+		MaxBackups: c.Backups(),
+		Compress:   c.Compress,
+	}
+}
+
+// Backups returns MaxBackups or its default.
+func (c LogRotateConfig) Backups() int {
+	if c.MaxBackups == nil {
+		return DefaultLogMaxBackups
+	}
+	return *c.MaxBackups
+}
+
+// ByteSize is a size in bytes. In the config file it may be written as a
+// plain number or with a KB, MB or GB suffix (powers of 1024).
+type ByteSize int64
+
+const (
+	KB ByteSize = 1 << (10 * (iota + 1))
+	MB
+	GB
+)
+
+var byteSizeUnits = []struct {
+	suffix string
+	size   ByteSize
+}{
+	{"GB", GB},
+	{"MB", MB},
+	{"KB", KB},
+	{"B", 1},
+}
+
+// ParseByteSize parses sizes such as "512", "64KB" or "1.5GB".
+func ParseByteSize(s string) (ByteSize, error) {
+	str := strings.ToUpper(strings.TrimSpace(s))
+	unit := ByteSize(1)
+	for _, u := range byteSizeUnits {
+		if strings.HasSuffix(str, u.suffix) {
+			str, unit = strings.TrimSpace(strings.TrimSuffix(str, u.suffix)), u.size
+			break
+		}
+	}
+
+	n, err := strconv.ParseFloat(str, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return ByteSize(n * float64(unit)), nil
+}
+
+func (b *ByteSize) UnmarshalYAML(node *yaml.Node) error {
+	var s string
+	if err := node.Decode(&s); err != nil {
+		return err
+	}
+	size, err := ParseByteSize(s)
+	if err != nil {
+		// TypeError не прерывает разбор остального файла
+		return &yaml.TypeError{Errors: []string{fmt.Sprintf("line %d: %v", node.Line, err)}}
+	}
+	*b = size
+	return nil
+}
+
+func (b ByteSize) String() string {
+	for _, u := range byteSizeUnits {
+		if b != 0 && b%u.size == 0 {
+			return fmt.Sprintf("%d%s", b/u.size, u.suffix)
+		}
+	}
+	return fmt.Sprintf("%dB", int64(b))
+}
+
+func applyLogDefaults(lc *LogRotateConfig) {
+	if lc.MaxSize == 0 {
+		lc.MaxSize = DefaultLogMaxSize
+	}
+}
+
+func applyLoggingDefaults(lc *LoggingConfig) error {
+	switch strings.ToLower(lc.Level) {
+	case "":
+		lc.Level = "info"
+	case "debug", "info", "warn", "warning", "error":
+	default:
+		return fmt.Errorf("logging: unknown level %q", lc.Level)
+	}
+
+	switch lc.Format {
+	case "":
+		lc.Format = "text"
+	case "text", "json":
+	default:
+		return fmt.Errorf("logging: unknown format %q", lc.Format)
+	}
+
+	if lc.File != "" {
+		if abs, err := filepath.Abs(lc.File); err == nil {
+			lc.File = abs
+		}
+	}
+	if lc.BufferSize == 0 {
+		lc.BufferSize = DefaultLogBufferSize
+	}
+	applyLogDefaults(&lc.Rotate)
+	return nil
+}
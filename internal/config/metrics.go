@@ -0,0 +1,9 @@
+package config
+
+// MetricsConfig configures the HTTP listener serving /metrics in the
+// Prometheus text format.
+type MetricsConfig struct {
+	// Address is the host:port to listen on, e.g. "127.0.0.1:9102"; the
+	// listener is disabled when it is empty.
+	Address string `yaml:"address,omitempty"`
+}
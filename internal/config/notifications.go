@@ -0,0 +1,150 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"time"
+)
+
+// NotifyEvent is a process lifecycle event notifications can be sent for.
+type NotifyEvent string
+
+const (
+	// NotifyFailed: the process exited unexpectedly.
+	NotifyFailed NotifyEvent = "failed"
+	// NotifyFatal: the process was given up on after exhausting its
+	// restarts or start retries.
+	NotifyFatal NotifyEvent = "fatal"
+	// NotifyRecovered: a failed or fatal process is running again.
+	NotifyRecovered NotifyEvent = "recovered"
+	// NotifyHealthChanged: a liveness or readiness probe changed its
+	// result.
+	NotifyHealthChanged NotifyEvent = "health_changed"
+)
+
+var notifyEvents = []NotifyEvent{NotifyFailed, NotifyFatal, NotifyRecovered, NotifyHealthChanged}
+
+// Defaults for notification settings left empty in the config file.
+const (
+	DefaultWebhookTimeout = 10 * time.Second
+	DefaultWebhookRetries = 3
+	DefaultWebhookBackoff = 1 * time.Second
+	DefaultCommandTimeout = 30 * time.Second
+)
+
+// NotificationsConfig lists the rules deciding who is told about process
+// lifecycle events.
+type NotificationsConfig struct {
+	Rules []NotificationRule `yaml:"rules,omitempty"`
+}
+
+// NotificationRule sends matching events to a webhook or a command.
+// Exactly one of Webhook and Command has to be set.
+type NotificationRule struct {
+	Name string `yaml:"name,omitempty"`
+	// Processes and Groups select the processes; a program name matches
+	// all of its instances and "group:*" is accepted as well. A rule
+	// without either matches every process.
+	Processes []string      `yaml:"processes,omitempty"`
+	Groups    []string      `yaml:"groups,omitempty"`
+	Events    []NotifyEvent `yaml:"events"`
+
+	Webhook *WebhookConfig `yaml:"webhook,omitempty"`
+	Command *CommandConfig `yaml:"command,omitempty"`
+}
+
+// WebhookConfig POSTs the event as JSON. Failed requests are retried
+// Retries times, doubling Backoff after every attempt.
+type WebhookConfig struct {
+	URL     string            `yaml:"url"`
+	Headers map[string]string `yaml:"headers,omitempty"`
+	Timeout time.Duration     `yaml:"timeout,omitempty"`
+	Retries *int              `yaml:"retries,omitempty"`
+	Backoff time.Duration     `yaml:"backoff,omitempty"`
+}
+
+// CommandConfig runs a local command with the event in GOSV_* environment
+// variables.
+type CommandConfig struct {
+	Command string            `yaml:"command"`
+	Args    []string          `yaml:"args,omitempty"`
+	Env     map[string]string `yaml:"env,omitempty"`
+	Timeout time.Duration     `yaml:"timeout,omitempty"`
+}
+
+// Matches reports whether the rule covers events of kind for a process.
+func (r NotificationRule) Matches(kind NotifyEvent, process, program, group string) bool {
+	if !slices.Contains(r.Events, kind) {
+		return false
+	}
+	if len(r.Processes) == 0 && len(r.Groups) == 0 {
+		return true
+	}
+	for _, name := range r.Processes {
+		if name == process || name == program || (group != "" && name == group+":*") {
+			return true
+		}
+	}
+	return group != "" && slices.Contains(r.Groups, group)
+}
+
+// Label names the rule in log messages.
+func (r NotificationRule) Label(i int) string {
+	if r.Name != "" {
+		return r.Name
+	}
+	return fmt.Sprintf("#%d", i+1)
+}
+
+func applyNotificationDefaults(nc *NotificationsConfig) error {
+	for i := range nc.Rules {
+		r := &nc.Rules[i]
+		if err := applyRuleDefaults(r); err != nil {
+			return fmt.Errorf("notification rule %s: %w", r.Label(i), err)
+		}
+	}
+	return nil
+}
+
+func applyRuleDefaults(r *NotificationRule) error {
+	if len(r.Events) == 0 {
+		return errors.New("no events")
+	}
+	for _, e := range r.Events {
+		if !slices.Contains(notifyEvents, e) {
+			return fmt.Errorf("unknown event %q", e)
+		}
+	}
+	if (r.Webhook == nil) == (r.Command == nil) {
+		return errors.New("exactly one of webhook and command must be set")
+	}
+
+	if w := r.Webhook; w != nil {
+		u, err := url.Parse(w.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid webhook url %q", w.URL)
+		}
+		if w.Timeout == 0 {
+			w.Timeout = DefaultWebhookTimeout
+		}
+		if w.Retries == nil {
+			retries := DefaultWebhookRetries
+			w.Retries = &retries
+		}
+		if w.Backoff == 0 {
+			w.Backoff = DefaultWebhookBackoff
+		}
+	}
+
+	if c := r.Command; c != nil {
+		if c.Command == "" {
+			return errors.New("command is empty")
+		}
+		if c.Timeout == 0 {
+			c.Timeout = DefaultCommandTimeout
+		}
+	}
+	return nil
+}
@@ -0,0 +1,229 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Problem is one thing wrong with a config file.
+type Problem struct {
+	Line    int // 0, если место в файле неизвестно
+	Message string
+}
+
+// ValidationError lists every problem found in a config, ordered by line.
+// It matches ErrInvalid.
+type ValidationError struct {
+	File     string
+	Problems []Problem
+}
+
+func (e *ValidationError) Error() string {
+	lines := make([]string, 0, len(e.Problems))
+	for _, p := range e.Problems {
+		lines = append(lines, e.Format(p))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrInvalid
+}
+
+// Format prefixes a problem with its position, like "gosv.yaml:12: ".
+func (e *ValidationError) Format(p Problem) string {
+	switch {
+	case p.Line > 0 && e.File != "":
+		return fmt.Sprintf("%s:%d: %s", e.File, p.Line, p.Message)
+	case p.Line > 0:
+		return fmt.Sprintf("line %d: %s", p.Line, p.Message)
+	case e.File != "":
+		return fmt.Sprintf("%s: %s", e.File, p.Message)
+	}
+	return p.Message
+}
+
+// validator collects problems instead of stopping at the first one.
+type validator struct {
+	file     string
+	problems []Problem
+}
+
+func (v *validator) add(line int, format string, args ...any) {
+	v.problems = append(v.problems, Problem{Line: line, Message: fmt.Sprintf(format, args...)})
+}
+
+// addErr records an error that may already carry a problem list or join
+// several errors.
+func (v *validator) addErr(line int, err error) {
+	var ve *ValidationError
+	if errors.As(err, &ve) {
+		v.problems = append(v.problems, ve.Problems...)
+		return
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, e := range joined.Unwrap() {
+			v.addErr(line, e)
+		}
+		return
+	}
+	v.add(line, "%v", err)
+}
+
+var (
+	yamlLineRe     = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+	unknownFieldRe = regexp.MustCompile(`^field (\S+) not found in type \S+$`)
+)
+
+// addYAML records an error message of the YAML decoder.
+func (v *validator) addYAML(msg string) {
+	line := 0
+	if m := yamlLineRe.FindStringSubmatch(msg); m != nil {
+		line, _ = strconv.Atoi(m[1])
+		msg = m[2]
+	}
+	if m := unknownFieldRe.FindStringSubmatch(msg); m != nil {
+		msg = fmt.Sprintf("unknown field %q", m[1])
+	}
+	v.add(line, "%s", msg)
+}
+
+func (v *validator) err() error {
+	if len(v.problems) == 0 {
+		return nil
+	}
+	// Проблемы без строки - в конце; на одной строке - по тексту, чтобы
+	// порядок не менялся от запуска к запуску
+	sort.SliceStable(v.problems, func(i, j int) bool {
+		li, lj := v.problems[i].Line, v.problems[j].Line
+		if li != lj {
+			return li != 0 && (lj == 0 || li < lj)
+		}
+		return v.problems[i].Message < v.problems[j].Message
+	})
+	return &ValidationError{File: v.file, Problems: v.problems}
+}
+
+// lineIndex remembers where the settings of a config file are, to point
+// problems at their lines. A nil index knows no lines.
+type lineIndex struct {
+	sections  map[string]int
+	processes []entryLines
+	groups    []entryLines
+}
+
+type entryLines struct {
+	line int
+	keys map[string]int
+}
+
+func indexLines(doc *yaml.Node) *lineIndex {
+	ix := &lineIndex{sections: make(map[string]int)}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return ix
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return ix
+	}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		ix.sections[key.Value] = key.Line
+		switch key.Value {
+		case "processes":
+			ix.processes = indexEntries(value)
+		case "groups":
+			ix.groups = indexEntries(value)
+		}
+	}
+	return ix
+}
+
+func indexEntries(seq *yaml.Node) []entryLines {
+	if seq.Kind != yaml.SequenceNode {
+		return nil
+	}
+	entries := make([]entryLines, 0, len(seq.Content))
+	for _, item := range seq.Content {
+		e := entryLines{line: item.Line, keys: make(map[string]int)}
+		if item.Kind == yaml.MappingNode {
+			for i := 0; i+1 < len(item.Content); i += 2 {
+				e.keys[item.Content[i].Value] = item.Content[i].Line
+			}
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+func (ix *lineIndex) section(key string) int {
+	if ix == nil {
+		return 0
+	}
+	return ix.sections[key]
+}
+
+// process returns the line of a key of the i-th process, or of the entry
+// itself if the key is not set.
+func (ix *lineIndex) process(i int, key string) int {
+	if ix == nil {
+		return 0
+	}
+	return entryLine(ix.processes, i, key)
+}
+
+func (ix *lineIndex) group(i int, key string) int {
+	if ix == nil {
+		return 0
+	}
+	return entryLine(ix.groups, i, key)
+}
+
+func entryLine(entries []entryLines, i int, key string) int {
+	if i >= len(entries) {
+		return 0
+	}
+	if line, ok := entries[i].keys[key]; ok {
+		return line
+	}
+	return entries[i].line
+}
+
+// checkHost verifies that the directory and the command of an instance
+// exist on this machine; it returns what is wrong with either.
+func checkHost(inst ProcessConfig) (dir, command string) {
+	if inst.Directory != "" {
+		if fi, err := os.Stat(inst.Directory); err != nil {
+			dir = fmt.Sprintf("directory %s does not exist", inst.Directory)
+		} else if !fi.IsDir() {
+			dir = fmt.Sprintf("%s is not a directory", inst.Directory)
+		}
+	}
+
+	if inst.Command == "" {
+		return dir, ""
+	}
+	// Относительный путь с разделителем запускается из directory
+	path := inst.Command
+	hasDir := strings.ContainsAny(path, `/\`)
+	if hasDir && !filepath.IsAbs(path) && inst.Directory != "" {
+		path = filepath.Join(inst.Directory, path)
+	}
+	if _, err := exec.LookPath(path); err != nil {
+		if hasDir {
+			command = fmt.Sprintf("command %s is not an executable file", path)
+		} else {
+			command = fmt.Sprintf("command %s not found in PATH", inst.Command)
+		}
+	}
+	return dir, command
+}
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestLoadReportsEveryProblem(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gosv.yaml")
+	data := "processes:\n" +
+		"  - {name: a, command: sleep, depends_on: [b], autorestart: sometimes}\n" +
+		"  - {name: b, command: sleep, depends_on: [a], readiness: {}, liveness: {}}\n"
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Problem{
+		{1, "dependency cycle: a -> b -> a"},
+		{2, `process a: unknown autorestart policy "sometimes"`},
+		{3, "process b: liveness probe: exactly one of http, tcp, exec or grpc must be set"},
+		{3, "process b: readiness probe: exactly one of http, tcp, exec or grpc must be set"},
+	}
+	// Порядок не должен зависеть от обхода map
+	for range 10 {
+		_, err := Load(path)
+		var ve *ValidationError
+		if !errors.As(err, &ve) {
+			t.Fatalf("Load: %v, want a ValidationError", err)
+		}
+		if !slices.Equal(ve.Problems, want) {
+			t.Fatalf("problems:\n%v\nwant:\n%v", ve.Problems, want)
+		}
+	}
+}
@@ -0,0 +1,316 @@
+package eventlistener
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kolkov/gosv/internal/logging"
+)
+
+// DefaultBufferSize is the number of events a pool keeps for its listeners
+// when no buffer_size is configured.
+const DefaultBufferSize = 10
+
+type event struct {
+	name       string
+	payload    string
+	serial     uint64
+	poolSerial uint64
+}
+
+// pool is the set of listener processes of one program. Every event is
+// delivered to exactly one listener of the pool.
+type pool struct {
+	name      string
+	mu        sync.Mutex
+	events    []string
+	size      int
+	buffer    []*event
+	serial    uint64
+	available chan struct{}
+}
+
+// Dispatcher buffers events for listener pools and serves listener
+// processes.
+type Dispatcher struct {
+	logger logging.Sink
+
+	mu     sync.Mutex
+	pools  map[string]*pool
+	serial uint64
+}
+
+// NewDispatcher creates a dispatcher and starts emitting TICK events.
+func NewDispatcher(logger logging.Sink) *Dispatcher {
+	d := &Dispatcher{
+		logger: logger,
+		pools:  make(map[string]*pool),
+	}
+	go d.tick()
+	return d
+}
+
+// SetPool creates or reconfigures the pool of a listener program. Events
+// buffered for an existing pool are kept.
+func (d *Dispatcher) SetPool(name string, events []string, bufferSize int) {
+	if bufferSize <= 0 {
+		bufferSize = DefaultBufferSize
+	}
+
+	d.mu.Lock()
+	p, ok := d.pools[name]
+	if !ok {
+		p = &pool{name: name, available: make(chan struct{}, 1)}
+		d.pools[name] = p
+	}
+	d.mu.Unlock()
+
+	p.mu.Lock()
+	p.events = events
+	p.size = bufferSize
+	p.mu.Unlock()
+}
+
+// RemovePool drops a pool together with its buffered events.
+func (d *Dispatcher) RemovePool(name string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.pools, name)
+}
+
+// Pools returns the names of all pools.
+func (d *Dispatcher) Pools() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	names := make([]string, 0, len(d.pools))
+	for name := range d.pools {
+		names = append(names, name)
+	}
+	return names
+}
+
+// Publish queues an event for every pool subscribed to its type.
+func (d *Dispatcher) Publish(name, payload string) {
+	d.mu.Lock()
+	d.serial++
+	serial := d.serial
+	pools := make([]*pool, 0, len(d.pools))
+	for _, p := range d.pools {
+		pools = append(pools, p)
+	}
+	d.mu.Unlock()
+
+	for _, p := range pools {
+		p.mu.Lock()
+		ok := subscribed(p.events, name)
+		p.mu.Unlock()
+		if ok {
+			d.accept(p, &event{name: name, payload: payload, serial: serial}, false)
+		}
+	}
+}
+
+// accept buffers an event, discarding the oldest one when the buffer is
+// full. Rejected events are put back at the head and keep their pool
+// serial.
+func (d *Dispatcher) accept(p *pool, e *event, head bool) {
+	p.mu.Lock()
+	if e.poolSerial == 0 {
+		p.serial++
+		e.poolSerial = p.serial
+	}
+	var discarded *event
+	if len(p.buffer) >= p.size && len(p.buffer) > 0 {
+		discarded = p.buffer[0]
+		p.buffer = p.buffer[1:]
+	}
+	if head {
+		p.buffer = append([]*event{e}, p.buffer...)
+	} else {
+		p.buffer = append(p.buffer, e)
+	}
+	p.mu.Unlock()
+
+	if discarded != nil {
+		d.log(logging.LevelError, "", "Pool %s event buffer overflowed, discarding event %d", p.name, discarded.serial)
+	}
+	p.signal()
+}
+
+// take removes the oldest buffered event, if any.
+func (p *pool) take() *event {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.buffer) == 0 {
+		return nil
+	}
+	e := p.buffer[0]
+	p.buffer = p.buffer[1:]
+	// Разбудить следующего слушателя, если события ещё остались
+	if len(p.buffer) > 0 {
+		p.signal()
+	}
+	return e
+}
+
+func (p *pool) signal() {
+	select {
+	case p.available <- struct{}{}:
+	default:
+	}
+}
+
+type messageKind int
+
+const (
+	msgReady messageKind = iota
+	msgResult
+	msgUnexpected
+)
+
+type message struct {
+	kind messageKind
+	data string
+}
+
+// readMessages parses what a listener writes to stdout until it closes.
+func readMessages(r io.Reader, out chan<- message) {
+	defer close(out)
+
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			return
+		}
+		switch {
+		case line == "READY\n":
+			out <- message{kind: msgReady}
+		case strings.HasPrefix(line, "RESULT "):
+			n, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "RESULT ")))
+			if err != nil || n < 0 {
+				out <- message{kind: msgUnexpected, data: line}
+				continue
+			}
+			data := make([]byte, n)
+			if _, err := io.ReadFull(br, data); err != nil {
+				return
+			}
+			out <- message{kind: msgResult, data: string(data)}
+		default:
+			out <- message{kind: msgUnexpected, data: line}
+		}
+	}
+}
+
+// Serve speaks the listener protocol with one process of a pool until its
+// stdout is closed. An event the listener rejects or does not acknowledge
+// before exiting is put back into the buffer.
+func (d *Dispatcher) Serve(poolName, listener string, stdin io.Writer, stdout io.Reader) {
+	msgs := make(chan message)
+	go readMessages(stdout, msgs)
+	// Не оставлять читателя висеть на отправке после выхода
+	defer func() {
+		go func() {
+			for range msgs {
+			}
+		}()
+	}()
+
+	d.mu.Lock()
+	p := d.pools[poolName]
+	d.mu.Unlock()
+	if p == nil {
+		d.log(logging.LevelError, listener, "No event listener pool %s", poolName)
+		return
+	}
+
+	for {
+		// Ждём READY
+		msg, ok := <-msgs
+		if !ok {
+			return
+		}
+		if msg.kind != msgReady {
+			d.log(logging.LevelWarn, listener, "Unexpected output while not ready: %q", msg.data)
+			continue
+		}
+
+		var e *event
+		for e == nil {
+			select {
+			case <-p.available:
+				e = p.take()
+			case msg, ok := <-msgs:
+				if !ok {
+					return
+				}
+				d.log(logging.LevelWarn, listener, "Unexpected output while ready: %q", msg.data)
+			}
+		}
+
+		data := header(e.serial, p.name, e.poolSerial, e.name, len(e.payload)) + e.payload
+		if _, err := io.WriteString(stdin, data); err != nil {
+			d.log(logging.LevelWarn, listener, "Failed to send event %d: %v, rebuffering", e.serial, err)
+			d.accept(p, e, true)
+			return
+		}
+
+		msg, ok = <-msgs
+		switch {
+		case !ok:
+			d.log(logging.LevelWarn, listener, "Exited while processing event %d, rebuffering", e.serial)
+			d.accept(p, e, true)
+			return
+		case msg.kind == msgResult && msg.data == "OK":
+			d.log(logging.LevelDebug, listener, "Event %d processed", e.serial)
+		case msg.kind == msgResult && msg.data == "FAIL":
+			d.log(logging.LevelInfo, listener, "Rejected event %d, rebuffering", e.serial)
+			d.accept(p, e, true)
+		default:
+			d.log(logging.LevelWarn, listener, "Unexpected result for event %d: %q, rebuffering", e.serial, msg.data)
+			d.accept(p, e, true)
+		}
+	}
+}
+
+// tick publishes TICK_5, TICK_60 and TICK_3600 whenever the wall clock
+// enters a new period, like supervisord does.
+func (d *Dispatcher) tick() {
+	periods := map[string]int64{Tick5: 5, Tick60: 60, Tick3600: 3600}
+	last := make(map[string]int64)
+	now := time.Now().Unix()
+	for name, period := range periods {
+		last[name] = now / period
+	}
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for t := range ticker.C {
+		now := t.Unix()
+		for _, name := range []string{Tick5, Tick60, Tick3600} {
+			if current := now / periods[name]; current != last[name] {
+				last[name] = current
+				d.Publish(name, fmt.Sprintf("when:%d", now))
+			}
+		}
+	}
+}
+
+func (d *Dispatcher) log(level logging.Level, process, format string, args ...any) {
+	if d.logger == nil {
+		return
+	}
+	d.logger.Write(logging.Record{
+		Level:   level,
+		Process: process,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
@@ -0,0 +1,157 @@
+package eventlistener
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"maps"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// listener plays a listener process on the other end of Serve.
+type listener struct {
+	t      *testing.T
+	stdin  *bufio.Reader // что пишет диспетчер
+	stdout io.WriteCloser
+}
+
+func (l *listener) send(s string) {
+	l.t.Helper()
+	if _, err := io.WriteString(l.stdout, s); err != nil {
+		l.t.Fatal(err)
+	}
+}
+
+// next announces READY and returns the header fields and payload of the
+// event it gets.
+func (l *listener) next() (map[string]string, string) {
+	l.t.Helper()
+	l.send("READY\n")
+	line, err := l.stdin.ReadString('\n')
+	if err != nil {
+		l.t.Fatal(err)
+	}
+	header := make(map[string]string)
+	for _, field := range strings.Fields(line) {
+		k, v, _ := strings.Cut(field, ":")
+		header[k] = v
+	}
+	n, err := strconv.Atoi(header["len"])
+	if err != nil {
+		l.t.Fatalf("header %q: %v", line, err)
+	}
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(l.stdin, payload); err != nil {
+		l.t.Fatal(err)
+	}
+	return header, string(payload)
+}
+
+func (l *listener) result(s string) {
+	l.send(fmt.Sprintf("RESULT %d\n%s", len(s), s))
+}
+
+func TestServeRoundTrip(t *testing.T) {
+	d := NewDispatcher(nil)
+	d.SetPool("audit", []string{ProcessState}, 0)
+
+	stdinR, stdinW := io.Pipe()
+	stdoutR, stdoutW := io.Pipe()
+	served := make(chan struct{})
+	go func() {
+		d.Serve("audit", "audit:00", stdinW, stdoutR)
+		close(served)
+	}()
+	l := &listener{t: t, stdin: bufio.NewReader(stdinR), stdout: stdoutW}
+
+	running := StatePayload("web", "web", "STARTING", "pid:42")
+	d.Publish(ProcessStateRunning, running)
+	// Пул не подписан на логи
+	d.Publish(ProcessLogStdout, LogPayload("web", "web", 42, "hello\n"))
+	d.Publish(ProcessStateExited, StatePayload("web", "web", "RUNNING", "expected:1", "pid:42"))
+
+	header, payload := l.next()
+	want := map[string]string{"ver": "3.0", "server": "supervisor", "serial": "1", "pool": "audit",
+		"poolserial": "1", "eventname": ProcessStateRunning, "len": strconv.Itoa(len(running))}
+	if !maps.Equal(header, want) {
+		t.Errorf("header %v, want %v", header, want)
+	}
+	if payload != running {
+		t.Errorf("payload %q, want %q", payload, running)
+	}
+
+	// Отклонённое событие приходит снова с теми же номерами
+	l.result("FAIL")
+	header, _ = l.next()
+	if header["serial"] != "1" || header["poolserial"] != "1" {
+		t.Errorf("after FAIL got serial %s poolserial %s, want the same event", header["serial"], header["poolserial"])
+	}
+	l.result("OK")
+
+	header, payload = l.next()
+	if header["eventname"] != ProcessStateExited || header["serial"] != "3" || header["poolserial"] != "2" {
+		t.Errorf("after OK got %v, want the exited event as serial 3, poolserial 2", header)
+	}
+	if !strings.HasPrefix(payload, "processname:web groupname:web from_state:RUNNING") {
+		t.Errorf("payload %q", payload)
+	}
+	l.result("OK")
+
+	// Слушатель завершился: Serve возвращается
+	stdoutW.Close()
+	select {
+	case <-served:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Serve did not return after the listener closed its stdout")
+	}
+}
+
+// An event the listener does not acknowledge before exiting goes to the
+// next listener of the pool.
+func TestServeRebuffersOnExit(t *testing.T) {
+	d := NewDispatcher(nil)
+	d.SetPool("audit", []string{Event}, 0)
+	d.Publish(ProcessStateFatal, StatePayload("web", "web", "BACKOFF"))
+
+	stdinR, stdinW := io.Pipe()
+	stdoutR, stdoutW := io.Pipe()
+	served := make(chan struct{})
+	go func() {
+		d.Serve("audit", "audit:00", stdinW, stdoutR)
+		close(served)
+	}()
+	first := &listener{t: t, stdin: bufio.NewReader(stdinR), stdout: stdoutW}
+	first.next()
+	stdoutW.Close()
+	<-served
+
+	stdinR, stdinW = io.Pipe()
+	stdoutR, stdoutW = io.Pipe()
+	defer stdoutW.Close()
+	go d.Serve("audit", "audit:01", stdinW, stdoutR)
+	second := &listener{t: t, stdin: bufio.NewReader(stdinR), stdout: stdoutW}
+	if header, _ := second.next(); header["eventname"] != ProcessStateFatal || header["serial"] != "1" {
+		t.Errorf("second listener got %v, want the fatal event", header)
+	}
+}
+
+func TestSubscribed(t *testing.T) {
+	for _, tt := range []struct {
+		events []string
+		name   string
+		want   bool
+	}{
+		{[]string{Event}, Tick60, true},
+		{[]string{ProcessState}, ProcessStateFatal, true},
+		{[]string{ProcessState}, ProcessLogStderr, false},
+		{[]string{ProcessStateExited}, ProcessStateFatal, false},
+		{[]string{Tick5, ProcessLog}, ProcessLogStdout, true},
+	} {
+		if got := subscribed(tt.events, tt.name); got != tt.want {
+			t.Errorf("subscribed(%v, %s) = %v, want %v", tt.events, tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+// Package eventlistener implements supervisord's event listener protocol.
+// Listener processes announce READY on stdout, receive one event at a
+// time on stdin as a header line followed by a payload, and answer with
+// "RESULT 2\nOK" or "RESULT 4\nFAIL".
+package eventlistener
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Event types, named as in supervisord. A listener subscribed to a type
+// also receives all of its subtypes, e.g. PROCESS_STATE covers
+// PROCESS_STATE_EXITED and EVENT covers everything.
+const (
+	Event = "EVENT"
+
+	ProcessState         = "PROCESS_STATE"
+	ProcessStateStarting = "PROCESS_STATE_STARTING"
+	ProcessStateRunning  = "PROCESS_STATE_RUNNING"
+	ProcessStateBackoff  = "PROCESS_STATE_BACKOFF"
+	ProcessStateStopping = "PROCESS_STATE_STOPPING"
+	ProcessStateExited   = "PROCESS_STATE_EXITED"
+	ProcessStateStopped  = "PROCESS_STATE_STOPPED"
+	ProcessStateFatal    = "PROCESS_STATE_FATAL"
+	ProcessStateUnknown  = "PROCESS_STATE_UNKNOWN"
+
+	ProcessLog       = "PROCESS_LOG"
+	ProcessLogStdout = "PROCESS_LOG_STDOUT"
+	ProcessLogStderr = "PROCESS_LOG_STDERR"
+
+	Tick     = "TICK"
+	Tick5    = "TICK_5"
+	Tick60   = "TICK_60"
+	Tick3600 = "TICK_3600"
+)
+
+// parents maps every event type to the type it is a subtype of.
+var parents = map[string]string{
+	ProcessState:         Event,
+	ProcessStateStarting: ProcessState,
+	ProcessStateRunning:  ProcessState,
+	ProcessStateBackoff:  ProcessState,
+	ProcessStateStopping: ProcessState,
+	ProcessStateExited:   ProcessState,
+	ProcessStateStopped:  ProcessState,
+	ProcessStateFatal:    ProcessState,
+	ProcessStateUnknown:  ProcessState,
+	ProcessLog:           Event,
+	ProcessLogStdout:     ProcessLog,
+	ProcessLogStderr:     ProcessLog,
+	Tick:                 Event,
+	Tick5:                Tick,
+	Tick60:               Tick,
+	Tick3600:             Tick,
+}
+
+// ValidEventType reports whether name is a known event type.
+func ValidEventType(name string) bool {
+	_, ok := parents[name]
+	return ok || name == Event
+}
+
+// subscribed reports whether a listener subscribed to events receives
+// events of type name.
+func subscribed(events []string, name string) bool {
+	for t := name; t != ""; t = parents[t] {
+		if slices.Contains(events, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// StatePayload formats the payload of a PROCESS_STATE event. extra holds
+// the state-specific fields such as "pid:123" or "tries:0".
+func StatePayload(process, group, fromState string, extra ...string) string {
+	fields := append([]string{
+		"processname:" + process,
+		"groupname:" + group,
+		"from_state:" + fromState,
+	}, extra...)
+	return strings.Join(fields, " ")
+}
+
+// LogPayload formats the payload of a PROCESS_LOG event.
+func LogPayload(process, group string, pid int, data string) string {
+	return fmt.Sprintf("processname:%s groupname:%s pid:%d\n%s", process, group, pid, data)
+}
+
+// header formats the line that precedes every payload.
+func header(serial uint64, pool string, poolSerial uint64, name string, length int) string {
+	return fmt.Sprintf("ver:3.0 server:supervisor serial:%d pool:%s poolserial:%d eventname:%s len:%d\n",
+		serial, pool, poolSerial, name, length)
+}
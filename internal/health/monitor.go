@@ -0,0 +1,88 @@
+package health
+
+import (
+	"context"
+	"time"
+
+	"github.com/kolkov/gosv/internal/config"
+)
+
+// Monitor runs a probe periodically and reports transitions between
+// healthy and unhealthy once the configured thresholds are crossed.
+type Monitor struct {
+	probe    Probe
+	cfg      config.ProbeConfig
+	healthy  bool
+	onChange func(healthy bool, err error)
+	// onFailure видит каждую неудачную проверку, а не только переходы
+	onFailure func(err error)
+}
+
+// NewMonitor creates a monitor that starts in the given state. Liveness
+// probes start healthy, readiness probes start unhealthy.
+func NewMonitor(cfg config.ProbeConfig, initial bool, onChange func(healthy bool, err error)) (*Monitor, error) {
+	probe, err := New(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Monitor{
+		probe:    probe,
+		cfg:      cfg,
+		healthy:  initial,
+		onChange: onChange,
+	}, nil
+}
+
+// OnFailure sets a function called after every failed check, before the
+// thresholds are applied. It must be set before Run.
+func (m *Monitor) OnFailure(f func(err error)) {
+	m.onFailure = f
+}
+
+// Run checks the probe every Interval until ctx is cancelled.
+func (m *Monitor) Run(ctx context.Context) {
+	select {
+	case <-ctx.Done():
+		return
+	case <-time.After(m.cfg.InitialDelay):
+	}
+
+	ticker := time.NewTicker(m.cfg.Interval)
+	defer ticker.Stop()
+
+	failures, successes := 0, 0
+	for {
+		checkCtx, cancel := context.WithTimeout(ctx, m.cfg.Timeout)
+		err := m.probe.Check(checkCtx)
+		cancel()
+
+		// Результат после остановки процесса уже не имеет смысла
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			if m.onFailure != nil {
+				m.onFailure(err)
+			}
+			failures++
+			successes = 0
+			if m.healthy && failures >= m.cfg.FailureThreshold {
+				m.healthy = false
+				m.onChange(false, err)
+			}
+		} else {
+			successes++
+			failures = 0
+			if !m.healthy && successes >= m.cfg.SuccessThreshold {
+				m.healthy = true
+				m.onChange(true, nil)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kolkov/gosv/internal/config"
+)
+
+// scriptedProbe returns the results of script in turn, then keeps
+// succeeding.
+type scriptedProbe struct {
+	script []error
+}
+
+func (p *scriptedProbe) Check(ctx context.Context) error {
+	if len(p.script) == 0 {
+		return nil
+	}
+	err := p.script[0]
+	p.script = p.script[1:]
+	return err
+}
+
+func TestMonitorThresholds(t *testing.T) {
+	down := errors.New("down")
+	// Одиночный сбой не меняет состояние, два подряд - меняют; обратно
+	// нужны три успеха подряд
+	probe := &scriptedProbe{script: []error{down, nil, down, down, nil, nil, down, nil, nil, nil}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var changes []bool
+	failures := 0
+	m := &Monitor{
+		probe: probe,
+		cfg: config.ProbeConfig{
+			Interval:         time.Millisecond,
+			Timeout:          time.Second,
+			FailureThreshold: 2,
+			SuccessThreshold: 3,
+		},
+		healthy: true,
+		onChange: func(healthy bool, err error) {
+			changes = append(changes, healthy)
+			if !healthy && err != down {
+				t.Errorf("unhealthy with %v, want %v", err, down)
+			}
+			if healthy {
+				cancel()
+			}
+		},
+	}
+	m.OnFailure(func(error) { failures++ })
+
+	done := make(chan struct{})
+	go func() {
+		m.Run(ctx)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("monitor did not become healthy again")
+	}
+
+	if len(changes) != 2 || changes[0] || !changes[1] {
+		t.Errorf("changes %v, want [false true]", changes)
+	}
+	if failures != 4 {
+		t.Errorf("%d failures reported, want 4", failures)
+	}
+}
@@ -0,0 +1,123 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os/exec"
+
+	"github.com/kolkov/gosv/internal/config"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// Probe performs a single health check. The context carries the probe
+// timeout.
+type Probe interface {
+	Check(ctx context.Context) error
+}
+
+// New builds the probe described by cfg.
+func New(cfg config.ProbeConfig) (Probe, error) {
+	switch {
+	case cfg.HTTP != nil:
+		return &HTTPProbe{cfg: *cfg.HTTP}, nil
+	case cfg.TCP != nil:
+		return &TCPProbe{cfg: *cfg.TCP}, nil
+	case cfg.Exec != nil:
+		return &ExecProbe{cfg: *cfg.Exec}, nil
+	case cfg.GRPC != nil:
+		return &GRPCProbe{cfg: *cfg.GRPC}, nil
+	default:
+		return nil, fmt.Errorf("probe type not set")
+	}
+}
+
+type HTTPProbe struct {
+	cfg config.HTTPProbe
+}
+
+func (p *HTTPProbe) Check(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.cfg.URL, nil)
+	if err != nil {
+		return err
+	}
+	for k, v := range p.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if p.cfg.ExpectedStatus != 0 {
+		if resp.StatusCode != p.cfg.ExpectedStatus {
+			return fmt.Errorf("GET %s: status %d, expected %d", p.cfg.URL, resp.StatusCode, p.cfg.ExpectedStatus)
+		}
+		return nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 400 {
+		return fmt.Errorf("GET %s: status %d", p.cfg.URL, resp.StatusCode)
+	}
+	return nil
+}
+
+type TCPProbe struct {
+	cfg config.TCPProbe
+}
+
+func (p *TCPProbe) Check(ctx context.Context) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", p.cfg.Address)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+type ExecProbe struct {
+	cfg config.ExecProbe
+}
+
+func (p *ExecProbe) Check(ctx context.Context) error {
+	out, err := exec.CommandContext(ctx, p.cfg.Command, p.cfg.Args...).CombinedOutput()
+	if err != nil {
+		if len(out) > 0 {
+			return fmt.Errorf("%w: %s", err, truncate(string(out), 200))
+		}
+		return err
+	}
+	return nil
+}
+
+type GRPCProbe struct {
+	cfg config.GRPCProbe
+}
+
+func (p *GRPCProbe) Check(ctx context.Context) error {
+	conn, err := grpc.NewClient(p.cfg.Address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: p.cfg.Service})
+	if err != nil {
+		return err
+	}
+	if resp.Status != healthpb.HealthCheckResponse_SERVING {
+		return fmt.Errorf("service %q is %s", p.cfg.Service, resp.Status)
+	}
+	return nil
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
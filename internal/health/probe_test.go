@@ -0,0 +1,104 @@
+package health
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kolkov/gosv/internal/config"
+	"google.golang.org/grpc"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestProbes(t *testing.T) {
+	web := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Probe") != "gosv" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		switch r.URL.Path {
+		case "/ok":
+		case "/moved":
+			w.WriteHeader(http.StatusFound)
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer web.Close()
+	headers := map[string]string{"X-Probe": "gosv"}
+
+	open, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer open.Close()
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed.Close()
+
+	grpcAddr := serveHealth(t)
+
+	// Тестовый бинарник без тестов завершается с кодом 0, с лишним флагом - с 2
+	self := os.Args[0]
+
+	tests := []struct {
+		name    string
+		cfg     config.ProbeConfig
+		wantErr string
+	}{
+		{"http ok", config.ProbeConfig{HTTP: &config.HTTPProbe{URL: web.URL + "/ok", Headers: headers}}, ""},
+		{"http redirect", config.ProbeConfig{HTTP: &config.HTTPProbe{URL: web.URL + "/moved", Headers: headers}}, ""},
+		{"http unavailable", config.ProbeConfig{HTTP: &config.HTTPProbe{URL: web.URL + "/down", Headers: headers}}, "status 503"},
+		{"http headers", config.ProbeConfig{HTTP: &config.HTTPProbe{URL: web.URL + "/ok"}}, "status 400"},
+		{"http expected status", config.ProbeConfig{HTTP: &config.HTTPProbe{URL: web.URL + "/down", Headers: headers, ExpectedStatus: 503}}, ""},
+		{"http unexpected status", config.ProbeConfig{HTTP: &config.HTTPProbe{URL: web.URL + "/ok", Headers: headers, ExpectedStatus: 204}}, "status 200, expected 204"},
+		{"tcp open", config.ProbeConfig{TCP: &config.TCPProbe{Address: open.Addr().String()}}, ""},
+		{"tcp closed", config.ProbeConfig{TCP: &config.TCPProbe{Address: closed.Addr().String()}}, "refused"},
+		{"exec ok", config.ProbeConfig{Exec: &config.ExecProbe{Command: self, Args: []string{"-test.run=^$"}}}, ""},
+		{"exec fails", config.ProbeConfig{Exec: &config.ExecProbe{Command: self, Args: []string{"-test.run=^$", "-no-such-flag"}}}, "no-such-flag"},
+		{"grpc serving", config.ProbeConfig{GRPC: &config.GRPCProbe{Address: grpcAddr}}, ""},
+		{"grpc not serving", config.ProbeConfig{GRPC: &config.GRPCProbe{Address: grpcAddr, Service: "jobs"}}, `service "jobs" is NOT_SERVING`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			probe, err := New(tt.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			err = probe.Check(ctx)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("Check: %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("Check: %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// serveHealth serves the gRPC health service with the overall status
+// SERVING and the service jobs NOT_SERVING.
+func serveHealth(t *testing.T) string {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hs := grpchealth.NewServer()
+	hs.SetServingStatus("jobs", healthpb.HealthCheckResponse_NOT_SERVING)
+	srv := grpc.NewServer()
+	healthpb.RegisterHealthServer(srv, hs)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+	return lis.Addr().String()
+}
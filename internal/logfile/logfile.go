@@ -0,0 +1,229 @@
+// Package logfile writes process output to files with size-based
+// rotation.
+package logfile
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Options controls rotation.
+type Options struct {
+	// MaxSize is the size at which the file is rotated; zero or a negative
+	// value disables rotation.
+	MaxSize int64
+	// MaxBackups is how many rotated files are kept as path.1, path.2, ...;
+	// with zero the file is truncated instead.
+	MaxBackups int
+	// Compress gzips rotated files to path.N.gz.
+	Compress bool
+}
+
+// Writer appends lines to a file and rotates it when it grows past
+// MaxSize. Writers are shared by path, so processes logging to the same
+// file write through one Writer and rotate it consistently.
+type Writer struct {
+	path string
+	opts Options
+	refs int // защищено registryMu
+
+	mu          sync.Mutex
+	file        *os.File // nil после неудачной ротации, до следующей записи
+	size        int64
+	closed      bool
+	compressing sync.WaitGroup
+}
+
+var (
+	registryMu sync.Mutex
+	registry   = make(map[string]*Writer)
+)
+
+// Open returns the Writer for path, creating the file and its directory
+// if needed. If the file is already open, the existing Writer and its
+// options are reused. Every Open must be paired with a Close.
+func Open(path string, opts Options) (*Writer, error) {
+	path = filepath.Clean(path)
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if w, ok := registry[path]; ok {
+		w.refs++
+		return w, nil
+	}
+
+	w := &Writer{path: path, opts: opts, refs: 1}
+	if err := w.open(os.O_APPEND); err != nil {
+		return nil, err
+	}
+	registry[path] = w
+	return w, nil
+}
+
+func (w *Writer) open(mode int) error {
+	if err := os.MkdirAll(filepath.Dir(w.path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|mode, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	w.file, w.size = f, info.Size()
+	return nil
+}
+
+// WriteLine appends line followed by a newline, rotating the file first if
+// the line would take it past MaxSize.
+func (w *Writer) WriteLine(line string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return os.ErrClosed
+	}
+	if w.file == nil {
+		if err := w.open(os.O_APPEND); err != nil {
+			return err
+		}
+	}
+
+	n := int64(len(line) + 1)
+	if w.opts.MaxSize > 0 && w.size > 0 && w.size+n > w.opts.MaxSize {
+		if err := w.rotate(); err != nil {
+			return fmt.Errorf("rotate %s: %w", w.path, err)
+		}
+	}
+
+	written, err := io.WriteString(w.file, line+"\n")
+	w.size += int64(written)
+	return err
+}
+
+// Close releases the Writer. The file is closed when the last user of the
+// path closes it, after any pending compression has finished.
+func (w *Writer) Close() error {
+	registryMu.Lock()
+	w.refs--
+	last := w.refs == 0
+	if last {
+		delete(registry, w.path)
+	}
+	registryMu.Unlock()
+
+	if !last {
+		return nil
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.closed = true
+	var err error
+	if w.file != nil {
+		err = w.file.Close()
+		w.file = nil
+	}
+	w.compressing.Wait()
+	return err
+}
+
+// rotate shifts the backups, moves the current file to path.1 and opens a
+// new one. It must be called with w.mu held.
+func (w *Writer) rotate() error {
+	err := w.file.Close()
+	w.file = nil
+	if err != nil {
+		return err
+	}
+
+	if w.opts.MaxBackups <= 0 {
+		return w.open(os.O_TRUNC)
+	}
+
+	// Сдвигать копии можно только после того, как сжатие предыдущей
+	// закончилось
+	w.compressing.Wait()
+
+	var errs []error
+	for _, name := range []string{w.backup(w.opts.MaxBackups), w.backup(w.opts.MaxBackups) + ".gz"} {
+		if err := os.Remove(name); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+	for i := w.opts.MaxBackups - 1; i >= 1; i-- {
+		for _, ext := range []string{"", ".gz"} {
+			if err := os.Rename(w.backup(i)+ext, w.backup(i+1)+ext); err != nil && !errors.Is(err, os.ErrNotExist) {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	if err := os.Rename(w.path, w.backup(1)); err != nil {
+		errs = append(errs, err)
+		// Не удалось сохранить копию: продолжаем писать в тот же файл
+		if err := w.open(os.O_APPEND); err != nil {
+			errs = append(errs, err)
+		}
+		return errors.Join(errs...)
+	}
+
+	if w.opts.Compress {
+		w.compressing.Add(1)
+		go func(name string) {
+			defer w.compressing.Done()
+			// При ошибке копия просто остаётся несжатой
+			_ = compress(name)
+		}(w.backup(1))
+	}
+
+	if err := w.open(os.O_TRUNC); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+func (w *Writer) backup(n int) string {
+	return fmt.Sprintf("%s.%d", w.path, n)
+}
+
+// compress gzips name to name.gz and removes the original.
+func compress(name string) error {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(name+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+
+	zw := gzip.NewWriter(dst)
+	zw.Name = filepath.Base(name)
+	_, err = io.Copy(zw, src)
+	if cerr := zw.Close(); err == nil {
+		err = cerr
+	}
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(name + ".gz")
+		return err
+	}
+
+	src.Close()
+	return os.Remove(name)
+}
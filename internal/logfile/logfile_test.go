@@ -0,0 +1,139 @@
+package logfile
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func writeLines(t *testing.T, w *Writer, from, to int) {
+	t.Helper()
+	for i := from; i <= to; i++ {
+		if err := w.WriteLine("line-" + strconv.Itoa(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// Every line is 7 bytes, so a file of 20 bytes holds two of them.
+func TestWriterRotatesKeepingBackups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "web.log")
+	w, err := Open(path, Options{MaxSize: 20, MaxBackups: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeLines(t, w, 1, 9)
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, want := range map[string]string{
+		path:        "line-9\n",
+		path + ".1": "line-7\nline-8\n",
+		path + ".2": "line-5\nline-6\n",
+	} {
+		if got := readFile(t, name); got != want {
+			t.Errorf("%s has %q, want %q", filepath.Base(name), got, want)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("%s.3 exists beyond MaxBackups: %v", filepath.Base(path), err)
+	}
+}
+
+func TestWriterTruncatesWithoutBackups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "web.log")
+	w, err := Open(path, Options{MaxSize: 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeLines(t, w, 1, 3)
+	w.Close()
+
+	if got := readFile(t, path); got != "line-3\n" {
+		t.Errorf("file has %q, want only the last line", got)
+	}
+	if _, err := os.Stat(path + ".1"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("backup written without MaxBackups: %v", err)
+	}
+}
+
+func TestWriterCompressesBackups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "web.log")
+	w, err := Open(path, Options{MaxSize: 20, MaxBackups: 2, Compress: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeLines(t, w, 1, 5)
+	// Close ждёт окончания сжатия
+	w.Close()
+
+	for name, want := range map[string]string{
+		path + ".1.gz": "line-3\nline-4\n",
+		path + ".2.gz": "line-1\nline-2\n",
+	} {
+		f, err := os.Open(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(zr)
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != want {
+			t.Errorf("%s has %q, want %q", filepath.Base(name), data, want)
+		}
+	}
+	if _, err := os.Stat(path + ".1"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("uncompressed backup left behind: %v", err)
+	}
+}
+
+// Writers are shared by path, so two users of one file rotate it once.
+func TestOpenSharesWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "web.log")
+	a, err := Open(path, Options{MaxSize: 20, MaxBackups: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Open(path, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a != b {
+		t.Fatal("Open returned a second Writer for the same path")
+	}
+	writeLines(t, a, 1, 2)
+	writeLines(t, b, 3, 3)
+	a.Close()
+	if err := b.WriteLine("line-4"); err != nil {
+		t.Errorf("WriteLine after the first Close: %v", err)
+	}
+	b.Close()
+	if err := b.WriteLine("line-5"); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("WriteLine after the last Close: %v, want %v", err, os.ErrClosed)
+	}
+
+	if got := readFile(t, path); got != "line-3\nline-4\n" {
+		t.Errorf("file has %q", got)
+	}
+}
@@ -0,0 +1,138 @@
+package logging
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Sink receives log records. Implementations must be safe for concurrent
+// use and should not block for long: records are delivered synchronously.
+type Sink interface {
+	Write(r Record)
+}
+
+// Pipeline fans records out to all of its sinks. It is itself a Sink, so
+// pipelines can be nested.
+type Pipeline struct {
+	mu     sync.RWMutex
+	sinks  map[int]Sink
+	nextID int
+	seq    atomic.Uint64
+}
+
+func NewPipeline(sinks ...Sink) *Pipeline {
+	p := &Pipeline{sinks: make(map[int]Sink)}
+	for _, s := range sinks {
+		p.Add(s)
+	}
+	return p
+}
+
+// Add attaches a sink and returns a function that detaches it again.
+func (p *Pipeline) Add(s Sink) (remove func()) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	id := p.nextID
+	p.nextID++
+	p.sinks[id] = s
+	return func() {
+		p.mu.Lock()
+		delete(p.sinks, id)
+		p.mu.Unlock()
+	}
+}
+
+// Write stamps records that have no sequence number, time or stream yet
+// and passes them to every sink.
+func (p *Pipeline) Write(r Record) {
+	if r.Seq == 0 {
+		r.Seq = p.seq.Add(1)
+	}
+	if r.Time.IsZero() {
+		r.Time = time.Now()
+	}
+	if r.Stream == "" {
+		r.Stream = StreamSupervisor
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	for _, s := range p.sinks {
+		s.Write(r)
+	}
+}
+
+// Subscribe returns a channel that receives every record matching f from
+// now on, and a function that ends the subscription. Records are dropped
+// rather than blocking the pipeline when the subscriber falls more than
+// buffer records behind.
+func (p *Pipeline) Subscribe(f Filter, buffer int) (<-chan Record, func()) {
+	ch := make(chan Record, buffer)
+	remove := p.Add(Filtered(subscriber(ch), f))
+	return ch, remove
+}
+
+type subscriber chan Record
+
+func (s subscriber) Write(r Record) {
+	select {
+	case s <- r:
+	default:
+	}
+}
+
+// Filter selects records by their fields. The zero Filter matches every
+// record.
+type Filter struct {
+	// Processes matches records of these processes. A program name also
+	// matches its instances ("worker" matches "worker:01").
+	Processes []string
+	// MinLevel drops records below this level.
+	MinLevel Level
+	// Streams matches records from these streams.
+	Streams []Stream
+	// Since drops records older than this.
+	Since time.Time
+}
+
+func (f Filter) Match(r Record) bool {
+	if r.Level < f.MinLevel {
+		return false
+	}
+	if !f.Since.IsZero() && r.Time.Before(f.Since) {
+		return false
+	}
+	if len(f.Streams) > 0 && !slices.Contains(f.Streams, r.Stream) {
+		return false
+	}
+	return f.matchProcess(r.Process)
+}
+
+func (f Filter) matchProcess(process string) bool {
+	if len(f.Processes) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(f.Processes, func(name string) bool {
+		return process == name || strings.HasPrefix(process, name+":")
+	})
+}
+
+type filtered struct {
+	sink   Sink
+	filter Filter
+}
+
+// Filtered returns a sink that passes only records matching f to s.
+func Filtered(s Sink, f Filter) Sink {
+	return &filtered{sink: s, filter: f}
+}
+
+func (f *filtered) Write(r Record) {
+	if f.filter.Match(r) {
+		f.sink.Write(r)
+	}
+}
@@ -0,0 +1,101 @@
+// Package logging carries supervisor messages and process output as
+// structured records through a fan-out pipeline of sinks.
+package logging
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Level is the severity of a record.
+type Level int8
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var levelNames = map[Level]string{
+	LevelDebug: "debug",
+	LevelInfo:  "info",
+	LevelWarn:  "warn",
+	LevelError: "error",
+}
+
+func (l Level) String() string {
+	if name, ok := levelNames[l]; ok {
+		return name
+	}
+	return fmt.Sprintf("level(%d)", int8(l))
+}
+
+// ParseLevel accepts level names in any case; "warning" is accepted as an
+// alias for warn.
+func ParseLevel(s string) (Level, error) {
+	name := strings.ToLower(strings.TrimSpace(s))
+	if name == "warning" {
+		return LevelWarn, nil
+	}
+	for l, n := range levelNames {
+		if n == name {
+			return l, nil
+		}
+	}
+	return LevelInfo, fmt.Errorf("unknown log level %q", s)
+}
+
+func (l Level) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+func (l *Level) UnmarshalText(text []byte) error {
+	level, err := ParseLevel(string(text))
+	if err != nil {
+		return err
+	}
+	*l = level
+	return nil
+}
+
+// Stream tells where a record came from: the supervisor itself or one of
+// a child's output streams.
+type Stream string
+
+const (
+	StreamSupervisor Stream = "supervisor"
+	StreamStdout     Stream = "stdout"
+	StreamStderr     Stream = "stderr"
+)
+
+// Record is a single log entry. Process is empty for messages that do not
+// concern a particular process; PID is set for process output. Seq is
+// assigned by the Pipeline and orders records across sinks.
+type Record struct {
+	Seq     uint64    `json:"-"`
+	Time    time.Time `json:"time"`
+	Level   Level     `json:"level"`
+	Process string    `json:"process,omitempty"`
+	PID     int       `json:"pid,omitempty"`
+	Stream  Stream    `json:"stream"`
+	Message string    `json:"message"`
+}
+
+// String renders the record without its timestamp, e.g.
+// "[WARN] [web] Liveness probe failed" or "[web][1234] listening on :80".
+func (r Record) String() string {
+	switch r.Stream {
+	case StreamStdout:
+		return fmt.Sprintf("[%s][%d] %s", r.Process, r.PID, r.Message)
+	case StreamStderr:
+		return fmt.Sprintf("[%s][%d][stderr] %s", r.Process, r.PID, r.Message)
+	}
+
+	level := strings.ToUpper(r.Level.String())
+	if r.Process == "" {
+		return fmt.Sprintf("[%s] %s", level, r.Message)
+	}
+	return fmt.Sprintf("[%s] [%s] %s", level, r.Process, r.Message)
+}
@@ -0,0 +1,195 @@
+package logging
+
+import (
+	"cmp"
+	"encoding/json"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/kolkov/gosv/internal/logfile"
+)
+
+const timeFormat = "2006/01/02 15:04:05"
+
+// Console writes records as text lines, prefixed with their time.
+type Console struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewConsole(w io.Writer) *Console {
+	return &Console{w: w}
+}
+
+func (c *Console) Write(r Record) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	io.WriteString(c.w, r.Time.Format(timeFormat)+" "+r.String()+"\n")
+}
+
+// JSONLines writes every record as one JSON object per line.
+type JSONLines struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+func NewJSONLines(w io.Writer) *JSONLines {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return &JSONLines{enc: enc}
+}
+
+func (j *JSONLines) Write(r Record) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.enc.Encode(r)
+}
+
+// File writes records to a rotating log file, as text or as JSON lines.
+type File struct {
+	w    *logfile.Writer
+	json bool
+}
+
+// OpenFile opens path for writing records. With asJSON the records are
+// written as JSON lines, otherwise in the Console format.
+func OpenFile(path string, opts logfile.Options, asJSON bool) (*File, error) {
+	w, err := logfile.Open(path, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &File{w: w, json: asJSON}, nil
+}
+
+func (f *File) Write(r Record) {
+	if f.json {
+		var b strings.Builder
+		enc := json.NewEncoder(&b)
+		enc.SetEscapeHTML(false)
+		if enc.Encode(r) == nil {
+			f.w.WriteLine(strings.TrimSuffix(b.String(), "\n"))
+		}
+		return
+	}
+	f.w.WriteLine(r.Time.Format(timeFormat) + " " + r.String())
+}
+
+func (f *File) Close() error {
+	return f.w.Close()
+}
+
+// Ring keeps the most recent records in memory.
+type Ring struct {
+	mu   sync.Mutex
+	buf  []Record
+	next int
+	full bool
+}
+
+func NewRing(size int) *Ring {
+	if size < 1 {
+		size = 1
+	}
+	return &Ring{buf: make([]Record, size)}
+}
+
+func (r *Ring) Write(rec Record) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.buf[r.next] = rec
+	r.next = (r.next + 1) % len(r.buf)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// Records returns the buffered records matching f, oldest first. With
+// limit > 0 only the last limit matches are returned.
+func (r *Ring) Records(f Filter, limit int) []Record {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var ordered []Record
+	if r.full {
+		ordered = append(ordered, r.buf[r.next:]...)
+	}
+	ordered = append(ordered, r.buf[:r.next]...)
+
+	var matched []Record
+	for _, rec := range ordered {
+		if f.Match(rec) {
+			matched = append(matched, rec)
+		}
+	}
+	if limit > 0 && len(matched) > limit {
+		matched = matched[len(matched)-limit:]
+	}
+	return matched
+}
+
+// ProcessRings keeps a separate Ring for every process, plus one for
+// supervisor messages that concern no process, so a chatty process cannot
+// push the output of the others out of the buffer.
+type ProcessRings struct {
+	mu    sync.Mutex
+	size  int
+	rings map[string]*Ring
+}
+
+func NewProcessRings(size int) *ProcessRings {
+	return &ProcessRings{size: size, rings: make(map[string]*Ring)}
+}
+
+func (pr *ProcessRings) Write(rec Record) {
+	pr.mu.Lock()
+	ring, ok := pr.rings[rec.Process]
+	if !ok {
+		ring = NewRing(pr.size)
+		pr.rings[rec.Process] = ring
+	}
+	pr.mu.Unlock()
+
+	ring.Write(rec)
+}
+
+// Retain drops the rings of the processes keep rejects, such as removed
+// programs, so that a process added later under the same name starts
+// with an empty buffer. The ring of supervisor messages is always kept.
+func (pr *ProcessRings) Retain(keep func(process string) bool) {
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+	for name := range pr.rings {
+		if name != "" && !keep(name) {
+			delete(pr.rings, name)
+		}
+	}
+}
+
+// Records returns the buffered records matching f from all rings, in the
+// order they were written. With limit > 0 only the last limit matches are
+// returned.
+func (pr *ProcessRings) Records(f Filter, limit int) []Record {
+	pr.mu.Lock()
+	rings := make([]*Ring, 0, len(pr.rings))
+	for name, ring := range pr.rings {
+		if f.matchProcess(name) {
+			rings = append(rings, ring)
+		}
+	}
+	pr.mu.Unlock()
+
+	var records []Record
+	for _, ring := range rings {
+		records = append(records, ring.Records(f, limit)...)
+	}
+	slices.SortFunc(records, func(a, b Record) int {
+		return cmp.Compare(a.Seq, b.Seq)
+	})
+	if limit > 0 && len(records) > limit {
+		records = records[len(records)-limit:]
+	}
+	return records
+}
@@ -0,0 +1,28 @@
+package logging
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestProcessRingsRetain(t *testing.T) {
+	pr := NewProcessRings(10)
+	for i, process := range []string{"", "web", "worker:00", "worker:01"} {
+		pr.Write(Record{Seq: uint64(i), Process: process, Message: process})
+	}
+
+	pr.Retain(func(process string) bool { return process == "worker:00" })
+	var got []string
+	for _, r := range pr.Records(Filter{}, 0) {
+		got = append(got, r.Message)
+	}
+	if want := []string{"", "worker:00"}; !slices.Equal(got, want) {
+		t.Errorf("records after Retain: %q, want %q", got, want)
+	}
+
+	// Процесс, снова добавленный под тем же именем, не видит старых строк
+	pr.Write(Record{Seq: 4, Process: "web", Message: "new"})
+	if got := pr.Records(Filter{Processes: []string{"web"}}, 0); len(got) != 1 || got[0].Message != "new" {
+		t.Errorf("records of web: %v, want only the new one", got)
+	}
+}
@@ -0,0 +1,256 @@
+// Package metrics serves process and API metrics in the Prometheus text
+// exposition format.
+package metrics
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kolkov/gosv/internal/process"
+)
+
+// Source provides the process status the metrics are computed from.
+type Source interface {
+	Status() map[string]*process.ProcessInfo
+}
+
+// Metrics computes process metrics from a Source on every scrape and
+// counts API requests.
+type Metrics struct {
+	src Source
+
+	mu       sync.Mutex
+	requests map[requestKey]uint64
+}
+
+type requestKey struct {
+	method, code string
+}
+
+func New(src Source) *Metrics {
+	return &Metrics{src: src, requests: make(map[requestKey]uint64)}
+}
+
+// CountRequest counts a finished API call by method and gRPC status code.
+func (m *Metrics) CountRequest(method, code string) {
+	m.mu.Lock()
+	m.requests[requestKey{method, code}]++
+	m.mu.Unlock()
+}
+
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	bw := bufio.NewWriter(w)
+	m.Write(bw)
+	bw.Flush()
+}
+
+// Write renders all metrics.
+func (m *Metrics) Write(out io.Writer) {
+	w := &writer{w: out}
+	statuses := m.src.Status()
+	names := make([]string, 0, len(statuses))
+	for name := range statuses {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	now := time.Now()
+
+	// Каждое семейство выводится целиком, как требует формат
+	perProcess := func(name, typ, help string, sample func(id string, info *process.ProcessInfo)) {
+		w.family(name, typ, help)
+		for _, id := range names {
+			sample(id, statuses[id])
+		}
+	}
+
+	perProcess("gosv_process_state", "gauge", "Current state of the process: 1 for the state it is in, 0 for the others.",
+		func(id string, info *process.ProcessInfo) {
+			for _, st := range process.Statuses() {
+				w.sample("gosv_process_state", boolValue(info.Status == st),
+					"process", id, "group", info.Group, "state", string(st))
+			}
+		})
+	perProcess("gosv_process_up", "gauge", "Whether the process is running.",
+		func(id string, info *process.ProcessInfo) {
+			w.sample("gosv_process_up", boolValue(info.Status == process.Running), "process", id, "group", info.Group)
+		})
+	perProcess("gosv_process_pid", "gauge", "PID of the process, 0 if it has none.",
+		func(id string, info *process.ProcessInfo) {
+			w.sample("gosv_process_pid", float64(activePID(info)), "process", id, "group", info.Group)
+		})
+	perProcess("gosv_process_uptime_seconds", "gauge", "Seconds since the process was started, 0 if it is not running.",
+		func(id string, info *process.ProcessInfo) {
+			var uptime float64
+			if activePID(info) != 0 && !info.StartTime.IsZero() {
+				uptime = now.Sub(info.StartTime).Seconds()
+			}
+			w.sample("gosv_process_uptime_seconds", uptime, "process", id, "group", info.Group)
+		})
+	perProcess("gosv_process_restarts", "gauge", "Restarts counted against the restart budget in the current window.",
+		func(id string, info *process.ProcessInfo) {
+			w.sample("gosv_process_restarts", float64(info.Restarts), "process", id, "group", info.Group)
+		})
+	perProcess("gosv_process_last_exit_code", "gauge", "Exit code of the last run of the process.",
+		func(id string, info *process.ProcessInfo) {
+			w.sample("gosv_process_last_exit_code", float64(info.ExitCode), "process", id, "group", info.Group)
+		})
+	perProcess("gosv_process_starts_total", "counter", "Times the process was started.",
+		func(id string, info *process.ProcessInfo) {
+			w.sample("gosv_process_starts_total", float64(info.Stats.Starts), "process", id, "group", info.Group)
+		})
+	perProcess("gosv_process_exits_total", "counter", "Times the process exited, by reason.",
+		func(id string, info *process.ProcessInfo) {
+			for _, reason := range sortedKeys(info.Stats.Exits) {
+				w.sample("gosv_process_exits_total", float64(info.Stats.Exits[reason]),
+					"process", id, "group", info.Group, "reason", string(reason))
+			}
+		})
+	perProcess("gosv_process_health_check_failures_total", "counter", "Failed health checks, by probe.",
+		func(id string, info *process.ProcessInfo) {
+			for _, probe := range sortedKeys(info.Stats.ProbeFailures) {
+				w.sample("gosv_process_health_check_failures_total", float64(info.Stats.ProbeFailures[probe]),
+					"process", id, "group", info.Group, "probe", probe)
+			}
+		})
+
+	// Ресурсы считаются по всему дереву процесса и есть только после замера.
+	// CPU и ввод-вывод - гауги: при выходе потомка сумма уменьшается
+	perUsage := func(name, typ, help string, value func(u process.Usage) float64) {
+		perProcess(name, typ, help, func(id string, info *process.ProcessInfo) {
+			if info.Usage.Sampled() {
+				w.sample(name, value(info.Usage), "process", id, "group", info.Group)
+			}
+		})
+	}
+	perUsage("gosv_process_cpu_percent", "gauge", "CPU used by the process and its descendants over the last sample interval, 100 per core.",
+		func(u process.Usage) float64 { return u.CPUPercent })
+	perUsage("gosv_process_cpu_seconds", "gauge", "CPU time used by the process and its live descendants; drops when a descendant exits.",
+		func(u process.Usage) float64 { return u.CPUTime.Seconds() })
+	perUsage("gosv_process_resident_memory_bytes", "gauge", "Resident memory of the process and its descendants.",
+		func(u process.Usage) float64 { return float64(u.RSS) })
+	perUsage("gosv_process_threads", "gauge", "Threads of the process and its descendants.",
+		func(u process.Usage) float64 { return float64(u.Threads) })
+	perUsage("gosv_process_open_fds", "gauge", "Open file descriptors of the process and its descendants.",
+		func(u process.Usage) float64 { return float64(u.FDs) })
+	perUsage("gosv_process_read_bytes", "gauge", "Bytes read from storage by the process and its live descendants; drops when a descendant exits.",
+		func(u process.Usage) float64 { return float64(u.ReadBytes) })
+	perUsage("gosv_process_written_bytes", "gauge", "Bytes written to storage by the process and its live descendants; drops when a descendant exits.",
+		func(u process.Usage) float64 { return float64(u.WriteBytes) })
+	perUsage("gosv_process_tree_size", "gauge", "Number of processes in the tree of the process.",
+		func(u process.Usage) float64 { return float64(u.Processes) })
+
+	m.mu.Lock()
+	keys := make([]requestKey, 0, len(m.requests))
+	for k := range m.requests {
+		keys = append(keys, k)
+	}
+	counts := make([]uint64, 0, len(keys))
+	slices.SortFunc(keys, func(a, b requestKey) int {
+		return strings.Compare(a.method+" "+a.code, b.method+" "+b.code)
+	})
+	for _, k := range keys {
+		counts = append(counts, m.requests[k])
+	}
+	m.mu.Unlock()
+
+	w.family("gosv_api_requests_total", "counter", "API calls handled, by method and gRPC status code.")
+	for i, k := range keys {
+		w.sample("gosv_api_requests_total", float64(counts[i]), "method", k.method, "code", k.code)
+	}
+}
+
+// activePID is the PID of a process that is up; Status keeps the PID of
+// the last run after the process exited.
+func activePID(info *process.ProcessInfo) int {
+	switch info.Status {
+	case process.Starting, process.Running, process.Stopping:
+		return info.PID
+	}
+	return 0
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func sortedKeys[K ~string, V any](m map[K]V) []K {
+	keys := make([]K, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// writer writes the text exposition format.
+type writer struct {
+	w io.Writer
+}
+
+func (w *writer) family(name, typ, help string) {
+	fmt.Fprintf(w.w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+// sample writes one value; labels are name, value pairs.
+func (w *writer) sample(name string, value float64, labels ...string) {
+	var b strings.Builder
+	b.WriteString(name)
+	if len(labels) > 0 {
+		b.WriteByte('{')
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(labels[i])
+			b.WriteString(`="`)
+			b.WriteString(labelEscaper.Replace(labels[i+1]))
+			b.WriteByte('"')
+		}
+		b.WriteByte('}')
+	}
+	b.WriteByte(' ')
+	b.WriteString(strconv.FormatFloat(value, 'g', -1, 64))
+	b.WriteByte('\n')
+	io.WriteString(w.w, b.String())
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// Serve serves the metrics on addr under /metrics. The returned function
+// stops the server.
+func Serve(m *Metrics, addr string) (stop func(), err error) {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", m)
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := srv.Serve(lis); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("[ERROR] metrics server: %v", err)
+		}
+	}()
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(ctx)
+	}, nil
+}
@@ -0,0 +1,68 @@
+//go:build unix
+
+package notify
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kolkov/gosv/internal/config"
+	"github.com/kolkov/gosv/internal/process"
+)
+
+func TestCommandEnvironment(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "env")
+	sink := make(recordSink, 16)
+	n := New(config.NotificationsConfig{Rules: []config.NotificationRule{{
+		Groups: []string{"back"},
+		Events: []config.NotifyEvent{config.NotifyHealthChanged},
+		Command: &config.CommandConfig{
+			Command: "sh",
+			Args:    []string{"-c", `env | grep -E '^(GOSV_|TEAM=)' | sort > "$OUT"`},
+			Env:     map[string]string{"OUT": out, "TEAM": "infra"},
+			Timeout: 5 * time.Second,
+		},
+	}}}, sink)
+	n.Notify(process.Notification{
+		Kind:    config.NotifyHealthChanged,
+		Time:    time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Process: "worker:00",
+		Program: "worker",
+		Group:   "back",
+		Status:  process.Running,
+		PID:     42,
+		Probe:   "readiness",
+		Healthy: false,
+		Message: "Readiness probe failed",
+	})
+	sink.waitFor(t, "Notification health_changed sent via rule #1")
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	env := string(data)
+	for _, want := range []string{
+		"GOSV_EVENT=health_changed", "GOSV_TIME=2026-01-02T03:04:05Z", "GOSV_PROCESS=worker:00",
+		"GOSV_PROGRAM=worker", "GOSV_GROUP=back", "GOSV_STATUS=running", "GOSV_PID=42",
+		"GOSV_EXIT_CODE=0", "GOSV_PROBE=readiness", "GOSV_HEALTHY=false",
+		"GOSV_MESSAGE=Readiness probe failed", "TEAM=infra",
+	} {
+		if !strings.Contains(env, want+"\n") {
+			t.Errorf("command environment lacks %s:\n%s", want, env)
+		}
+	}
+}
+
+func TestCommandFailure(t *testing.T) {
+	sink := make(recordSink, 16)
+	n := New(config.NotificationsConfig{Rules: []config.NotificationRule{{
+		Events:  []config.NotifyEvent{config.NotifyFailed},
+		Command: &config.CommandConfig{Command: "sh", Args: []string{"-c", "echo no route >&2; exit 7"}, Timeout: 5 * time.Second},
+	}}}, sink)
+	n.Notify(process.Notification{Kind: config.NotifyFailed, Process: "web"})
+	sink.waitFor(t, "Notification failed via rule #1 failed: exit status 7: no route")
+}
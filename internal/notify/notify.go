@@ -0,0 +1,257 @@
+// Package notify tells people about process lifecycle events: matching
+// notification rules POST the event to a webhook or run a local command.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kolkov/gosv/internal/config"
+	"github.com/kolkov/gosv/internal/logging"
+	"github.com/kolkov/gosv/internal/process"
+)
+
+// queueSize is how many notifications may wait for delivery per rule
+// before new ones are dropped.
+const queueSize = 64
+
+// Payload is the JSON document sent to webhooks.
+type Payload struct {
+	Event    config.NotifyEvent `json:"event"`
+	Time     time.Time          `json:"time"`
+	Host     string             `json:"host,omitempty"`
+	Process  string             `json:"process"`
+	Program  string             `json:"program,omitempty"`
+	Group    string             `json:"group,omitempty"`
+	Status   process.Status     `json:"status"`
+	PID      int                `json:"pid,omitempty"`
+	ExitCode int                `json:"exit_code"`
+	Error    string             `json:"error,omitempty"`
+	Restarts int                `json:"restarts"`
+	Probe    string             `json:"probe,omitempty"`
+	Healthy  *bool              `json:"healthy,omitempty"`
+	Message  string             `json:"message"`
+}
+
+// Notifier implements process.Notifier. Every rule has its own queue, so
+// notifications for a rule are delivered in order and a slow webhook does
+// not hold up the others.
+type Notifier struct {
+	logger logging.Sink
+	client *http.Client
+	host   string
+
+	mu     sync.RWMutex
+	queues []*queue
+}
+
+type queue struct {
+	label string
+	rule  config.NotificationRule
+	ch    chan process.Notification
+}
+
+func New(cfg config.NotificationsConfig, logger logging.Sink) *Notifier {
+	host, _ := os.Hostname()
+	n := &Notifier{
+		logger: logger,
+		client: &http.Client{},
+		host:   host,
+	}
+	n.Configure(cfg)
+	return n
+}
+
+// Configure replaces the rules. Notifications already queued for the old
+// rules are still delivered.
+func (n *Notifier) Configure(cfg config.NotificationsConfig) {
+	queues := make([]*queue, 0, len(cfg.Rules))
+	for i, rule := range cfg.Rules {
+		q := &queue{
+			label: rule.Label(i),
+			rule:  rule,
+			ch:    make(chan process.Notification, queueSize),
+		}
+		queues = append(queues, q)
+		go n.worker(q)
+	}
+
+	n.mu.Lock()
+	old := n.queues
+	n.queues = queues
+	n.mu.Unlock()
+
+	for _, q := range old {
+		close(q.ch)
+	}
+}
+
+// Notify queues the notification for every matching rule.
+func (n *Notifier) Notify(ev process.Notification) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	for _, q := range n.queues {
+		if !q.rule.Matches(ev.Kind, ev.Process, ev.Program, ev.Group) {
+			continue
+		}
+		select {
+		case q.ch <- ev:
+		default:
+			// Логируем в горутине: Notify вызывается под мьютексом процесса
+			go n.log(logging.LevelWarn, ev.Process, "Notification queue of rule %s is full, dropping %s", q.label, ev.Kind)
+		}
+	}
+}
+
+func (n *Notifier) worker(q *queue) {
+	for ev := range q.ch {
+		var err error
+		switch {
+		case q.rule.Webhook != nil:
+			err = n.postWebhook(q.rule.Webhook, n.payload(ev))
+		case q.rule.Command != nil:
+			err = n.runCommand(q.rule.Command, n.payload(ev))
+		}
+		if err != nil {
+			n.log(logging.LevelError, ev.Process, "Notification %s via rule %s failed: %v", ev.Kind, q.label, err)
+		} else {
+			n.log(logging.LevelDebug, ev.Process, "Notification %s sent via rule %s", ev.Kind, q.label)
+		}
+	}
+}
+
+func (n *Notifier) payload(ev process.Notification) Payload {
+	p := Payload{
+		Event:    ev.Kind,
+		Time:     ev.Time,
+		Host:     n.host,
+		Process:  ev.Process,
+		Program:  ev.Program,
+		Group:    ev.Group,
+		Status:   ev.Status,
+		PID:      ev.PID,
+		ExitCode: ev.ExitCode,
+		Restarts: ev.Restarts,
+		Probe:    ev.Probe,
+		Message:  ev.Message,
+	}
+	if ev.Error != nil {
+		p.Error = ev.Error.Error()
+	}
+	if ev.Kind == config.NotifyHealthChanged {
+		healthy := ev.Healthy
+		p.Healthy = &healthy
+	}
+	return p
+}
+
+// postWebhook sends the payload, retrying with exponential backoff. Any
+// non-2xx response counts as a failure.
+func (n *Notifier) postWebhook(w *config.WebhookConfig, p Payload) error {
+	body, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+
+	retries := config.DefaultWebhookRetries
+	if w.Retries != nil {
+		retries = *w.Retries
+	}
+	backoff := w.Backoff
+	for attempt := 0; ; attempt++ {
+		err = n.post(w, body)
+		if err == nil || attempt >= retries {
+			return err
+		}
+		n.log(logging.LevelWarn, p.Process, "Webhook %s failed: %v, retrying in %v", w.URL, err, backoff)
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+func (n *Notifier) post(w *config.WebhookConfig, body []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), w.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "gosv")
+	for k, v := range w.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// runCommand runs the command with the event in GOSV_* variables.
+func (n *Notifier) runCommand(c *config.CommandConfig, p Payload) error {
+	ctx, cancel := context.WithTimeout(context.Background(), c.Timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, c.Command, c.Args...)
+	cmd.Env = os.Environ()
+	for k, v := range c.Env {
+		cmd.Env = append(cmd.Env, k+"="+v)
+	}
+	cmd.Env = append(cmd.Env, commandEnv(p)...)
+
+	out, err := cmd.CombinedOutput()
+	if ctx.Err() != nil {
+		return fmt.Errorf("timed out after %v", c.Timeout)
+	}
+	if err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
+
+func commandEnv(p Payload) []string {
+	env := []string{
+		"GOSV_EVENT=" + string(p.Event),
+		"GOSV_TIME=" + p.Time.Format(time.RFC3339),
+		"GOSV_HOST=" + p.Host,
+		"GOSV_PROCESS=" + p.Process,
+		"GOSV_PROGRAM=" + p.Program,
+		"GOSV_GROUP=" + p.Group,
+		"GOSV_STATUS=" + string(p.Status),
+		"GOSV_PID=" + strconv.Itoa(p.PID),
+		"GOSV_EXIT_CODE=" + strconv.Itoa(p.ExitCode),
+		"GOSV_ERROR=" + p.Error,
+		"GOSV_RESTARTS=" + strconv.Itoa(p.Restarts),
+		"GOSV_MESSAGE=" + p.Message,
+	}
+	if p.Healthy != nil {
+		env = append(env, "GOSV_PROBE="+p.Probe, "GOSV_HEALTHY="+strconv.FormatBool(*p.Healthy))
+	}
+	return env
+}
+
+func (n *Notifier) log(level logging.Level, process, format string, args ...any) {
+	if n.logger != nil {
+		n.logger.Write(logging.Record{Level: level, Process: process, Message: fmt.Sprintf(format, args...)})
+	}
+}
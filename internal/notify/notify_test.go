@@ -0,0 +1,131 @@
+package notify
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kolkov/gosv/internal/config"
+	"github.com/kolkov/gosv/internal/logging"
+	"github.com/kolkov/gosv/internal/process"
+)
+
+// recordSink hands the records of a Notifier to the test.
+type recordSink chan logging.Record
+
+func (s recordSink) Write(r logging.Record) {
+	s <- r
+}
+
+// waitFor returns the first record containing msg.
+func (s recordSink) waitFor(t *testing.T, msg string) logging.Record {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case r := <-s:
+			if strings.Contains(r.Message, msg) {
+				return r
+			}
+		case <-timeout:
+			t.Fatalf("no log record containing %q", msg)
+		}
+	}
+}
+
+func TestWebhookRetriesAndPayload(t *testing.T) {
+	bodies := make(chan []byte, 4)
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.Header.Get("Authorization") != "Bearer hook" || r.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		// Первая попытка проваливается, вторая проходит
+		if calls == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		bodies <- body
+	}))
+	defer srv.Close()
+
+	retries := 2
+	sink := make(recordSink, 16)
+	n := New(config.NotificationsConfig{Rules: []config.NotificationRule{{
+		Name:      "oncall",
+		Processes: []string{"worker"},
+		Events:    []config.NotifyEvent{config.NotifyFailed},
+		Webhook: &config.WebhookConfig{
+			URL:     srv.URL,
+			Headers: map[string]string{"Authorization": "Bearer hook"},
+			Timeout: time.Second,
+			Retries: &retries,
+			Backoff: time.Millisecond,
+		},
+	}}}, sink)
+
+	when := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	// Ни событие, ни процесс не подходят под правило
+	n.Notify(process.Notification{Kind: config.NotifyFatal, Process: "worker:00", Program: "worker"})
+	n.Notify(process.Notification{Kind: config.NotifyFailed, Process: "web", Program: "web"})
+	n.Notify(process.Notification{
+		Kind:     config.NotifyFailed,
+		Time:     when,
+		Process:  "worker:01",
+		Program:  "worker",
+		Group:    "back",
+		Status:   process.Failed,
+		PID:      42,
+		ExitCode: 3,
+		Error:    errors.New("exit status 3"),
+		Restarts: 1,
+		Message:  "Process exited unexpectedly",
+	})
+
+	sink.waitFor(t, "Webhook "+srv.URL+" failed: unexpected status 502")
+	sink.waitFor(t, "Notification failed sent via rule oncall")
+
+	var got Payload
+	if err := json.Unmarshal(<-bodies, &got); err != nil {
+		t.Fatal(err)
+	}
+	got.Host = ""
+	want := Payload{Event: config.NotifyFailed, Time: when, Process: "worker:01", Program: "worker", Group: "back",
+		Status: process.Failed, PID: 42, ExitCode: 3, Error: "exit status 3", Restarts: 1, Message: "Process exited unexpectedly"}
+	if got != want {
+		t.Errorf("payload %+v, want %+v", got, want)
+	}
+	select {
+	case body := <-bodies:
+		t.Errorf("unexpected second delivery: %s", body)
+	default:
+	}
+}
+
+func TestWebhookGivesUp(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	retries := 1
+	sink := make(recordSink, 16)
+	n := New(config.NotificationsConfig{Rules: []config.NotificationRule{{
+		Events:  []config.NotifyEvent{config.NotifyFatal},
+		Webhook: &config.WebhookConfig{URL: srv.URL, Timeout: time.Second, Retries: &retries, Backoff: time.Millisecond},
+	}}}, sink)
+	n.Notify(process.Notification{Kind: config.NotifyFatal, Process: "web"})
+
+	r := sink.waitFor(t, "Notification fatal via rule #1 failed: unexpected status 500")
+	if r.Level != logging.LevelError || r.Process != "web" {
+		t.Errorf("record %+v, want an error of web", r)
+	}
+}
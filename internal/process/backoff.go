@@ -0,0 +1,65 @@
+package process
+
+import (
+	"math/rand/v2"
+	"time"
+
+	"github.com/kolkov/gosv/internal/config"
+)
+
+// restartBudget tracks restarts inside the configured sliding window and
+// the backoff delay before the next one.
+type restartBudget struct {
+	cfg     config.RestartConfig
+	delay   time.Duration
+	history []time.Time
+}
+
+func newRestartBudget(cfg config.RestartConfig) *restartBudget {
+	return &restartBudget{cfg: cfg, delay: cfg.InitialDelay}
+}
+
+// reset forgets all recorded restarts and returns to the initial delay.
+func (b *restartBudget) reset() {
+	b.delay = b.cfg.InitialDelay
+	b.history = b.history[:0]
+}
+
+// count returns the number of restarts inside the window ending at now.
+func (b *restartBudget) count(now time.Time) int {
+	if b.cfg.Window > 0 {
+		cutoff := now.Add(-b.cfg.Window)
+		i := 0
+		for i < len(b.history) && b.history[i].Before(cutoff) {
+			i++
+		}
+		b.history = b.history[i:]
+	}
+	return len(b.history)
+}
+
+// exhausted reports whether no restarts are left in the window.
+func (b *restartBudget) exhausted(now time.Time) bool {
+	return b.cfg.RestartLimit() >= 0 && b.count(now) >= b.cfg.RestartLimit()
+}
+
+// next records a restart at now and returns how long to wait before it.
+func (b *restartBudget) next(now time.Time) time.Duration {
+	b.history = append(b.history, now)
+	return b.backoff()
+}
+
+// backoff returns the current delay and grows the stored one by
+// Multiplier up to MaxDelay. Jitter is applied only to the returned value.
+func (b *restartBudget) backoff() time.Duration {
+	wait := b.delay
+	if b.cfg.Jitter > 0 {
+		wait = time.Duration(float64(wait) * (1 + b.cfg.Jitter*(2*rand.Float64()-1)))
+	}
+
+	b.delay = time.Duration(float64(b.delay) * b.cfg.Multiplier)
+	if b.delay > b.cfg.MaxDelay {
+		b.delay = b.cfg.MaxDelay
+	}
+	return wait
+}
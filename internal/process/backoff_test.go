@@ -0,0 +1,32 @@
+package process
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kolkov/gosv/internal/config"
+)
+
+func TestRestartBudgetExhausted(t *testing.T) {
+	limit := func(n int) *int { return &n }
+	now := time.Now()
+	for _, tt := range []struct {
+		max      *int
+		restarts int
+		want     bool
+	}{
+		{nil, config.DefaultMaxRestarts - 1, false},
+		{nil, config.DefaultMaxRestarts, true},
+		{limit(0), 0, true},
+		{limit(2), 1, false},
+		{limit(-1), 100, false},
+	} {
+		b := newRestartBudget(config.RestartConfig{MaxRestarts: tt.max})
+		for range tt.restarts {
+			b.next(now)
+		}
+		if got := b.exhausted(now); got != tt.want {
+			t.Errorf("max %v after %d restarts: exhausted = %v, want %v", b.cfg.RestartLimit(), tt.restarts, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,15 @@
+package process
+
+import "errors"
+
+// Errors returned by the Manager; test for them with errors.Is. The
+// returned errors wrap them together with the name of the process.
+var (
+	ErrNotFound       = errors.New("not found")
+	ErrAlreadyExists  = errors.New("already exists")
+	ErrAlreadyRunning = errors.New("already running")
+	ErrNotRunning     = errors.New("not running")
+	ErrInvalidSignal  = errors.New("unsupported signal")
+	// ErrStartFailed means the process used up its start retries.
+	ErrStartFailed = errors.New("failed to start")
+)
@@ -0,0 +1,96 @@
+package process
+
+import (
+	"sync"
+	"time"
+)
+
+// Event describes a status transition of a process. ExitCode and Error
+// describe the last exit and are meaningful for Exited, Failed, Backoff and
+// Fatal; Tries is the number of failed starts in a row.
+type Event struct {
+	Time     time.Time
+	Process  string
+	Program  string
+	Group    string
+	From     Status
+	To       Status
+	PID      int
+	ExitCode int
+	Error    error
+	Tries    int
+}
+
+// EventBus fans process events out to subscribers.
+type EventBus struct {
+	mu     sync.Mutex
+	subs   map[int]chan Event
+	nextID int
+}
+
+func NewEventBus() *EventBus {
+	return &EventBus{subs: make(map[int]chan Event)}
+}
+
+// Subscribe returns a channel receiving every event from now on and a
+// function that ends the subscription and closes the channel. Events are
+// dropped rather than blocking a process when the subscriber falls more
+// than buffer events behind.
+func (b *EventBus) Subscribe(buffer int) (<-chan Event, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id := b.nextID
+	b.nextID++
+	ch := make(chan Event, buffer)
+	b.subs[id] = ch
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subs, id)
+			b.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+func (b *EventBus) publish(e Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, ch := range b.subs {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+// setStatus changes the status and publishes the transition. It must be
+// called with p.mu held.
+func (p *Process) setStatus(s Status) {
+	from := p.Status
+	p.Status = s
+	if from == s || p.events == nil {
+		return
+	}
+
+	e := Event{
+		Time:     time.Now(),
+		Process:  p.ID,
+		Program:  p.Config.Program,
+		Group:    p.Config.Group,
+		From:     from,
+		To:       s,
+		ExitCode: p.exitCode,
+		Error:    p.exitError,
+		Tries:    p.startRetries,
+	}
+	// При Starting в p.Cmd ещё предыдущий запуск
+	if s != Starting && p.Cmd != nil && p.Cmd.Process != nil {
+		e.PID = p.Cmd.Process.Pid
+	}
+	p.events.publish(e)
+}
@@ -1,16 +1,20 @@
 package process
 
 import (
-	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
+	"slices"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
 
 	"github.com/kolkov/gosv/internal/config"
+	"github.com/kolkov/gosv/internal/health"
+	"github.com/kolkov/gosv/internal/logging"
 )
 
 type Status string
@@ -21,20 +25,45 @@ const (
 	Running  Status = "running"
 	Stopping Status = "stopping"
 	Failed   Status = "failed"
+	Exited   Status = "exited"  // завершился с ожидаемым кодом выхода
+	Backoff  Status = "backoff" // ожидает повторного запуска
+	Fatal    Status = "fatal"   // попытки запуска исчерпаны
 )
 
-const (
-	MaxRestarts         = 5
-	InitialRestartDelay = 1 * time.Second
-	MaxRestartDelay     = 30 * time.Second
-)
+var statuses = []Status{Stopped, Starting, Running, Stopping, Failed, Exited, Backoff, Fatal}
+
+// Statuses returns all statuses a process can be in.
+func Statuses() []Status {
+	return slices.Clone(statuses)
+}
+
+// ParseStatus checks that s names a status.
+func ParseStatus(s string) (Status, error) {
+	if slices.Contains(statuses, Status(s)) {
+		return Status(s), nil
+	}
+	return "", fmt.Errorf("unknown status %q", s)
+}
 
 type ProcessInfo struct {
 	PID       int
 	Status    Status
 	StartTime time.Time
 	Restarts  int
-	ExitError error
+	// StartRetries - число неудачных попыток запуска подряд
+	StartRetries int
+	// MaxRestarts и RestartWindow описывают бюджет перезапусков процесса
+	MaxRestarts   int
+	RestartWindow time.Duration
+	ExitCode      int
+	ExitError     error
+	// Ready - результат readiness-пробы; без пробы совпадает с Running
+	Ready bool
+	// Group - группа процесса из секции groups, пустая если её нет
+	Group string
+	Stats Stats
+	// Usage - ресурсы процесса и его потомков по последнему замеру
+	Usage Usage
 }
 
 type Process struct {
@@ -44,124 +73,347 @@ type Process struct {
 	Config       config.ProcessConfig
 	restart      bool
 	quit         chan struct{}
+	done         chan struct{}
 	mu           sync.Mutex
 	startTime    time.Time
-	restartCount int
-	restartDelay time.Duration
+	budget       *restartBudget
+	startRetries int
+	started      chan error // результат первого запуска для Manager.Start
+	ready        bool
+	exitCode     int
 	exitError    error
-	logger       func(string) // Функция для логирования
+	logger       logging.Sink
+	events       *EventBus
+	protocol     ProtocolHandler
+	notifier     Notifier
+	degraded     bool // упал и ещё не восстановился, для recovered
+	stats        Stats
+	usage        Usage
+	// oneShot - процесс ждут с completed_successfully, его успешный
+	// выход до start_secs не считается неудачным запуском
+	oneShot bool
+	// completed - процесс успешно завершился после последнего запуска;
+	// в отличие от статуса Exited не теряется при перезапуске политикой
+	completed bool
 }
 
 type Manager struct {
 	processes map[string]*Process
+	programs  map[string][]string // программа -> имена её экземпляров
+	groups    map[string][]string // группа -> имена процессов
 	mu        sync.RWMutex
-	logger    func(string) // Общий логгер
+	logger    logging.Sink // Общий логгер
+	events    *EventBus
+	protocol  ProtocolHandler
+	notifier  Notifier
 }
 
-func NewManager(logger func(string)) *Manager {
+// NewManager creates a manager that logs to logger and publishes status
+// transitions to events; both may be nil.
+func NewManager(logger logging.Sink, events *EventBus) *Manager {
 	return &Manager{
 		processes: make(map[string]*Process),
+		programs:  make(map[string][]string),
+		groups:    make(map[string][]string),
 		logger:    logger,
+		events:    events,
 	}
 }
 
-func (m *Manager) SetLogger(logger func(string)) {
+// SetProtocolHandler sets the handler serving event listeners. It applies
+// to processes added afterwards; without it listeners run as regular
+// processes.
+func (m *Manager) SetProtocolHandler(h ProtocolHandler) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	m.protocol = h
+}
 
-	m.logger = logger
-
-	// Обновляем логгеры для всех процессов
-	for _, proc := range m.processes {
-		proc.mu.Lock()
-		proc.logger = logger
-		proc.mu.Unlock()
+func (m *Manager) log(level logging.Level, format string, args ...any) {
+	if m.logger != nil {
+		m.logger.Write(logging.Record{Level: level, Message: fmt.Sprintf(format, args...)})
 	}
 }
 
+// AddProcess registers a program, expanding it into numprocs instances.
 func (m *Manager) AddProcess(cfg config.ProcessConfig) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	p := &Process{
-		ID:           cfg.Name,
-		Config:       cfg,
-		Status:       Stopped,
-		quit:         make(chan struct{}),
-		restartDelay: InitialRestartDelay,
-		logger:       m.logger, // Используем общий логгер
+	if _, err := m.addLocked(cfg); err != nil {
+		m.log(logging.LevelError, "Failed to add process %s: %v", cfg.Name, err)
 	}
+	m.markOneShotsLocked()
+}
 
-	if cfg.Autorestart == "always" {
-		p.restart = true
+// addLocked registers the instances of a program and returns their names.
+// It must be called with m.mu held.
+func (m *Manager) addLocked(cfg config.ProcessConfig) ([]string, error) {
+	instances, err := cfg.Instances()
+	if err != nil {
+		return nil, err
 	}
 
-	m.processes[cfg.Name] = p
+	names := make([]string, 0, len(instances))
+	for _, inst := range instances {
+		p := &Process{
+			ID:       inst.Name,
+			Config:   inst,
+			Status:   Stopped,
+			quit:     make(chan struct{}),
+			budget:   newRestartBudget(inst.Restart),
+			logger:   m.logger, // Используем общий логгер
+			events:   m.events,
+			protocol: m.protocol,
+			notifier: m.notifier,
+		}
+		m.processes[inst.Name] = p
+
+		if inst.Program != inst.Name {
+			m.programs[inst.Program] = append(m.programs[inst.Program], inst.Name)
+		}
+		if inst.Group != "" {
+			m.groups[inst.Group] = append(m.groups[inst.Group], inst.Name)
+		}
+		names = append(names, inst.Name)
+	}
+	return names, nil
 }
 
-func (m *Manager) Start(name string) error {
+// resolve returns the processes addressed by name, in start order: a
+// single process or instance, all instances of a program, or all members
+// of a group. Programs and groups may also be written as "name:*".
+func (m *Manager) resolve(name string) ([]*Process, error) {
 	m.mu.RLock()
-	p, exists := m.processes[name]
-	m.mu.RUnlock()
+	defer m.mu.RUnlock()
 
-	if !exists {
-		return fmt.Errorf("process not found: %s", name)
+	if p, ok := m.processes[name]; ok {
+		return []*Process{p}, nil
 	}
 
-	p.mu.Lock()
-	defer p.mu.Unlock()
+	base := strings.TrimSuffix(name, ":*")
+	if ids, ok := m.groups[base]; ok {
+		return m.subsetLocked(ids), nil
+	}
+	if ids, ok := m.programs[base]; ok {
+		return m.subsetLocked(ids), nil
+	}
+	if p, ok := m.processes[base]; ok {
+		return []*Process{p}, nil
+	}
+	return nil, fmt.Errorf("process %w: %s", ErrNotFound, name)
+}
+
+// subsetLocked returns the processes with the given names in start order.
+// It must be called with m.mu held.
+func (m *Manager) subsetLocked(names []string) []*Process {
+	want := make(map[string]bool, len(names))
+	for _, n := range names {
+		want[n] = true
+	}
+
+	var procs []*Process
+	for _, p := range m.orderedLocked() {
+		if want[p.ID] {
+			procs = append(procs, p)
+		}
+	}
+	return procs
+}
+
+// Start starts a process or instance. For programs and groups every member
+// that is not already active is started in dependency order. Start waits
+// until they are running or have failed to start.
+func (m *Manager) Start(name string) error {
+	procs, err := m.resolve(name)
+	if err != nil {
+		return err
+	}
+	if len(procs) == 1 {
+		return m.start(procs[0])
+	}
+	return m.startSet(procs)
+}
 
+func (m *Manager) start(p *Process) error {
+	p.mu.Lock()
 	// Разрешаем запуск только остановленных процессов
-	if p.Status != Stopped && p.Status != Failed {
-		return fmt.Errorf("process is already running: %s", name)
+	switch p.Status {
+	case Stopped, Failed, Exited, Fatal:
+	default:
+		p.mu.Unlock()
+		return fmt.Errorf("process is %w: %s", ErrAlreadyRunning, p.ID)
 	}
 
-	p.Status = Starting
+	p.restart = true // до явной остановки решения принимает политика
+	p.exitCode = 0
 	p.exitError = nil
-	p.restartCount = 0
+	p.completed = false
+	p.startRetries = 0
+	p.budget.reset()
+	p.setStatus(Starting)
 	p.quit = make(chan struct{}) // Создаем новый канал
-	go p.run()
+	p.done = make(chan struct{})
+	p.started = make(chan error, 1)
+	started := p.started
+	go p.run(p.quit, p.done)
+	p.mu.Unlock()
+
+	// Ждём, пока процесс продержится start_secs или исчерпает попытки
+	return <-started
+}
+
+// Stop stops a process or instance, or every active member of a program or
+// group in reverse dependency order, and waits until they have exited.
+func (m *Manager) Stop(name string) error {
+	procs, err := m.resolve(name)
+	if err != nil {
+		return err
+	}
+	if len(procs) == 1 {
+		return m.stop(procs[0])
+	}
+	m.stopSet(procs)
 	return nil
 }
 
-func (m *Manager) StartAll() error {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+// Restart stops whatever is active among the addressed processes and
+// starts them all again.
+func (m *Manager) Restart(name string) error {
+	procs, err := m.resolve(name)
+	if err != nil {
+		return err
+	}
+	m.stopSet(procs)
+	return m.startSet(procs)
+}
 
-	var firstError error
-	for _, p := range m.processes {
-		if p.Config.Autostart {
-			if err := m.Start(p.ID); err != nil {
-				if firstError == nil {
-					firstError = err
-				}
-				if m.logger != nil {
-					m.logger(fmt.Sprintf("[ERROR] Failed to autostart process %s: %v", p.ID, err))
-				}
-			}
+// Signal sends a signal by name, e.g. "HUP" or "SIGUSR1", to the running
+// processes addressed by name. Like stop_signal it is delivered to each
+// process group. It fails if none of the processes is running.
+func (m *Manager) Signal(name, signal string) error {
+	sig, err := ParseSignal(signal)
+	if err != nil {
+		return err
+	}
+	procs, err := m.resolve(name)
+	if err != nil {
+		return err
+	}
+
+	sent := 0
+	var errs []error
+	for _, p := range procs {
+		p.mu.Lock()
+		cmd := p.Cmd
+		running := p.Status == Running || p.Status == Starting
+		p.mu.Unlock()
+		if !running || cmd == nil || cmd.Process == nil {
+			continue
+		}
+		if err := signalGroup(cmd, sig); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", p.ID, err))
+			continue
 		}
+		p.log(logging.LevelInfo, "Sent signal %s", signal)
+		sent++
 	}
-	return firstError
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	if sent == 0 {
+		return fmt.Errorf("process is %w: %s", ErrNotRunning, name)
+	}
+	return nil
 }
 
-func (m *Manager) Stop(name string) error {
+// StartGroup starts all members of a group in dependency order.
+func (m *Manager) StartGroup(group string) error {
+	procs, err := m.group(group)
+	if err != nil {
+		return err
+	}
+	return m.startSet(procs)
+}
+
+// StopGroup stops all members of a group in reverse dependency order.
+func (m *Manager) StopGroup(group string) error {
+	procs, err := m.group(group)
+	if err != nil {
+		return err
+	}
+	m.stopSet(procs)
+	return nil
+}
+
+// RestartGroup stops and then starts all members of a group.
+func (m *Manager) RestartGroup(group string) error {
+	procs, err := m.group(group)
+	if err != nil {
+		return err
+	}
+	m.stopSet(procs)
+	return m.startSet(procs)
+}
+
+// Members returns the names of the processes addressed by name, in start
+// order, the same way Start and Stop resolve it.
+func (m *Manager) Members(name string) ([]string, error) {
+	procs, err := m.resolve(name)
+	return processNames(procs), err
+}
+
+// GroupMembers returns the names of the processes in a group.
+func (m *Manager) GroupMembers(group string) ([]string, error) {
+	procs, err := m.group(group)
+	return processNames(procs), err
+}
+
+func processNames(procs []*Process) []string {
+	names := make([]string, 0, len(procs))
+	for _, p := range procs {
+		names = append(names, p.ID)
+	}
+	return names
+}
+
+func (m *Manager) group(name string) ([]*Process, error) {
 	m.mu.RLock()
-	p, exists := m.processes[name]
-	m.mu.RUnlock()
+	defer m.mu.RUnlock()
 
-	if !exists {
-		return fmt.Errorf("process not found: %s", name)
+	ids, ok := m.groups[name]
+	if !ok {
+		return nil, fmt.Errorf("group %w: %s", ErrNotFound, name)
 	}
+	return m.subsetLocked(ids), nil
+}
 
+func (m *Manager) stop(p *Process) error {
 	p.mu.Lock()
-	defer p.mu.Unlock()
-
 	// Разрешаем остановку только активных процессов
-	if p.Status != Running && p.Status != Starting {
-		return fmt.Errorf("process is not running: %s", name)
+	if !p.active() {
+		p.mu.Unlock()
+		return fmt.Errorf("process is %w: %s", ErrNotRunning, p.ID)
 	}
+	done := p.requestStop()
+	p.mu.Unlock()
 
-	p.Status = Stopping
+	// Ждём, пока run() доведёт остановку до конца
+	<-done
+	return nil
+}
+
+// active reports whether the run loop is alive and can be asked to stop.
+// It must be called with p.mu held.
+func (p *Process) active() bool {
+	return p.Status == Running || p.Status == Starting || p.Status == Backoff
+}
+
+// requestStop marks the process as stopping and wakes up its run loop.
+// It must be called with p.mu held and returns the channel that is closed
+// once the run loop has finished.
+func (p *Process) requestStop() chan struct{} {
+	p.setStatus(Stopping)
 	p.restart = false
 
 	// Закрываем quit канал только если он существует
@@ -169,25 +421,7 @@ func (m *Manager) Stop(name string) error {
 		close(p.quit)
 		p.quit = nil
 	}
-	return nil
-}
-
-func (m *Manager) StopAll() {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
-
-	for _, p := range m.processes {
-		p.mu.Lock()
-		if p.Status == Running || p.Status == Starting {
-			p.Status = Stopping
-			p.restart = false
-			if p.quit != nil {
-				close(p.quit)
-				p.quit = make(chan struct{})
-			}
-		}
-		p.mu.Unlock()
-	}
+	return p.done
 }
 
 func (m *Manager) Status() map[string]*ProcessInfo {
@@ -198,51 +432,56 @@ func (m *Manager) Status() map[string]*ProcessInfo {
 	for name, proc := range m.processes {
 		proc.mu.Lock()
 		info := &ProcessInfo{
-			Status:    proc.Status,
-			StartTime: proc.startTime,
-			Restarts:  proc.restartCount,
-			ExitError: proc.exitError,
+			Status:        proc.Status,
+			StartTime:     proc.startTime,
+			Restarts:      proc.budget.count(time.Now()),
+			StartRetries:  proc.startRetries,
+			MaxRestarts:   proc.Config.Restart.RestartLimit(),
+			RestartWindow: proc.Config.Restart.Window,
+			ExitCode:      proc.exitCode,
+			ExitError:     proc.exitError,
+			Group:         proc.Config.Group,
+			Stats:         proc.stats.clone(),
+			Usage:         proc.usage,
 		}
 
 		if proc.Cmd != nil && proc.Cmd.Process != nil {
 			info.PID = proc.Cmd.Process.Pid
 		}
 
+		info.Ready = proc.Status == Running && (proc.ready || proc.Config.Readiness == nil)
+
 		statuses[name] = info
 		proc.mu.Unlock()
 	}
 	return statuses
 }
 
-func (p *Process) run() {
+func (p *Process) run(quit, done chan struct{}) {
+	defer close(done)
 	defer func() {
 		p.mu.Lock()
-		p.Status = Stopped
+		// Failed/Exited/Fatal сохраняем, чтобы была видна причина остановки
+		switch p.Status {
+		case Stopping, Starting, Running, Backoff:
+			p.setStatus(Stopped)
+		}
+		p.reportStart(fmt.Errorf("process %s stopped before it finished starting", p.ID))
 		p.mu.Unlock()
 	}()
 
 	for {
 		p.mu.Lock()
-		p.Status = Starting
+		p.setStatus(Starting)
 		p.startTime = time.Now()
 		p.mu.Unlock()
 
-		// Reset restart delay for new runs
-		p.restartDelay = InitialRestartDelay
+		p.log(logging.LevelInfo, "Starting process: %s %v", p.Config.Command, p.Config.Args)
 
-		if p.logger != nil {
-			p.logger(fmt.Sprintf("[INFO] Starting process: %s %v", p.Config.Command, p.Config.Args))
-		}
-
-		ctx, cancel := context.WithCancel(context.Background())
-		cmd := exec.CommandContext(ctx, p.Config.Command, p.Config.Args...)
+		cmd := exec.Command(p.Config.Command, p.Config.Args...)
 		cmd.Dir = p.Config.Directory
 
-		// Windows-specific process attributes
-		cmd.SysProcAttr = &syscall.SysProcAttr{
-			CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP,
-			HideWindow:    true,
-		}
+		configureCmd(cmd)
 
 		cmd.Env = os.Environ()
 		for k, v := range p.Config.Environment {
@@ -250,143 +489,403 @@ func (p *Process) run() {
 		}
 
 		// Create output pipes
-		stdout, _ := cmd.StdoutPipe()
-		stderr, _ := cmd.StderrPipe()
-
-		p.mu.Lock()
-		p.Cmd = cmd
-		p.mu.Unlock()
+		pipes, err := p.openPipes(cmd)
+		if err == nil {
+			p.mu.Lock()
+			p.Cmd = cmd
+			p.mu.Unlock()
 
-		// Start the process
-		if err := cmd.Start(); err != nil {
+			// Start the process
+			err = cmd.Start()
+			if err != nil {
+				pipes.close()
+			} else {
+				pipes.closeChildEnds()
+			}
+		}
+		if err != nil {
 			p.mu.Lock()
-			p.Status = Failed
 			p.exitError = fmt.Errorf("start failed: %w", err)
 			p.mu.Unlock()
-			if p.logger != nil {
-				p.logger(fmt.Sprintf("[ERROR] Process %s failed to start: %v", p.ID, err))
+			p.log(logging.LevelError, "Process failed to start: %v", err)
+			if !p.startFailed(quit) {
+				return
 			}
-			return
-		}
-
-		if p.logger != nil {
-			p.logger(fmt.Sprintf("[INFO] Process %s started with PID: %d", p.ID, cmd.Process.Pid))
+			continue
 		}
 
 		p.mu.Lock()
-		p.Status = Running
+		p.stats.Starts++
 		p.mu.Unlock()
+		p.log(logging.LevelInfo, "Process started with PID: %d", cmd.Process.Pid)
 
 		// Real-time output handling
-		go func() {
-			scanner := bufio.NewScanner(stdout)
-			for scanner.Scan() {
-				log := fmt.Sprintf("[%s][%d] %s", p.ID, cmd.Process.Pid, scanner.Text())
-				if p.logger != nil {
-					p.logger(log)
-				}
-			}
-		}()
-
-		go func() {
-			scanner := bufio.NewScanner(stderr)
-			for scanner.Scan() {
-				log := fmt.Sprintf("[%s][%d][ERROR] %s", p.ID, cmd.Process.Pid, scanner.Text())
-				if p.logger != nil {
-					p.logger(log)
-				}
-			}
-		}()
+		p.readOutput(pipes, cmd.Process.Pid)
 
-		done := make(chan error, 1)
+		exited := make(chan error, 1)
 		go func() {
-			done <- cmd.Wait()
+			exited <- cmd.Wait()
 		}()
 
-		select {
-		case <-p.quit:
-			p.logger("[DEBUG] Received stop signal")
-			cancel()
+		probeCtx, stopProbes := context.WithCancel(context.Background())
+		livenessFailed := p.startProbes(probeCtx)
 
-			if p.logger != nil {
-				p.log(fmt.Sprintf("[INFO] Stopping process: %s (PID: %d)", p.ID, cmd.Process.Pid))
-			}
+		// Процесс считается запущенным, только проработав start_secs
+		grace := p.Config.StartGrace()
+		started := time.NewTimer(grace)
+		if grace == 0 {
+			started.Stop()
+			p.markRunning()
+		}
 
-			// Принудительное завершение по таймауту
+		// Процесс, проработавший ResetAfter, считается стабильным
+		stable := time.NewTimer(p.Config.Restart.ResetAfter)
+		var probeErr error
+	wait:
+		for {
 			select {
-			case <-done:
-				p.logger("[DEBUG] Process stopped gracefully")
-			case <-time.After(p.Config.StopWait):
-				p.log("[WARN] Force killing process after timeout")
-				cmd.Process.Kill()
-				<-done
-			}
-			return
-
-		case err := <-done:
-			cancel()
-			p.mu.Lock()
-			if err != nil {
-				p.Status = Failed
-				p.exitError = fmt.Errorf("exit error: %w", err)
-				if p.logger != nil {
-					p.logger(fmt.Sprintf("[ERROR] Process %s (PID: %d) exited with error: %v", p.ID, cmd.Process.Pid, err))
-				}
-			} else {
-				p.Status = Stopped
-				if p.logger != nil {
-					p.logger(fmt.Sprintf("[INFO] Process %s (PID: %d) exited normally", p.ID, cmd.Process.Pid))
+			case <-quit:
+				started.Stop()
+				stable.Stop()
+				stopProbes()
+				p.terminate(cmd, exited)
+				p.mu.Lock()
+				p.countExit(ExitStopped)
+				p.mu.Unlock()
+				return
+
+			case probeErr = <-livenessFailed:
+				p.log(logging.LevelWarn, "Liveness probe failed: %v, restarting", probeErr)
+				p.terminate(cmd, exited)
+				started.Stop()
+				stable.Stop()
+				break wait
+
+			case <-started.C:
+				p.markRunning()
+
+			case <-stable.C:
+				p.mu.Lock()
+				if p.budget.count(time.Now()) > 0 {
+					p.log(logging.LevelInfo, "Process stable for %v, resetting restart counter", p.Config.Restart.ResetAfter)
 				}
+				p.budget.reset()
+				p.mu.Unlock()
+
+			case err = <-exited:
+				started.Stop()
+				stable.Stop()
+				break wait
 			}
-			p.mu.Unlock()
 		}
 
+		stopProbes()
+		p.reapGroup(cmd)
+		code := cmd.ProcessState.ExitCode()
+		// Процесс, убитый по liveness-пробе, всегда считается упавшим
+		expected := probeErr == nil && p.expectedExit(code)
 		p.mu.Lock()
-		currentRestart := p.restart
-		currentRestartCount := p.restartCount
+		wasRunning := p.Status == Running
+		succeeded := probeErr == nil && expected && code == 0
+		if succeeded {
+			p.completed = true
+		}
+		// Одноразовая задача вроде миграции может успешно закончить до start_secs
+		finishedEarly := !wasRunning && succeeded && p.oneShot
+		startFailed := !wasRunning && !finishedEarly
+		switch {
+		case probeErr != nil:
+			p.countExit(ExitLiveness)
+		case startFailed:
+			p.countExit(ExitStartFailed)
+		case expected:
+			p.countExit(ExitExpected)
+		default:
+			p.countExit(ExitUnexpected)
+		}
+		p.ready = false
+		p.exitCode = code
+		if probeErr != nil {
+			err = fmt.Errorf("liveness probe failed: %w", probeErr)
+		} else if err == nil && (!expected || startFailed) {
+			err = fmt.Errorf("exit status %d", code)
+		}
+		if err != nil {
+			p.exitError = fmt.Errorf("exit error: %w", err)
+		} else {
+			p.exitError = nil
+		}
 		p.mu.Unlock()
 
-		if !currentRestart {
+		if startFailed {
+			// Выход до истечения start_secs считается неудачным запуском
+			p.log(logging.LevelError, "Process (PID: %d) exited within %v of starting (code %d)",
+				cmd.Process.Pid, grace, code)
+			if !p.startFailed(quit) {
+				return
+			}
+			continue
+		}
+
+		p.mu.Lock()
+		if finishedEarly {
+			p.startRetries = 0
+			p.reportStart(nil)
+		}
+		if expected {
+			p.setStatus(Exited)
+			p.log(logging.LevelInfo, "Process (PID: %d) exited with expected code %d", cmd.Process.Pid, code)
+		} else {
+			p.setStatus(Failed)
+			p.log(logging.LevelError, "Process (PID: %d) exited with error: %v", cmd.Process.Pid, err)
+			p.notify(config.NotifyFailed, "Process exited with error: %v", err)
+		}
+
+		restart := p.shouldRestart(code)
+		if probeErr != nil {
+			restart = p.Config.Autorestart != config.RestartNever
+		}
+		if !p.restart || !restart {
+			p.mu.Unlock()
 			return
 		}
 
 		// Check restart limits
-		if currentRestartCount >= MaxRestarts {
-			p.mu.Lock()
-			p.Status = Failed
+		now := time.Now()
+		if p.budget.exhausted(now) {
+			p.setStatus(Fatal)
 			p.restart = false
-			if p.logger != nil {
-				p.logger(fmt.Sprintf("[WARN] Process %s reached max restarts (%d), stopping", p.ID, MaxRestarts))
-			}
+			p.log(logging.LevelWarn, "Process reached max restarts (%d), stopping", p.Config.Restart.RestartLimit())
+			p.notify(config.NotifyFatal, "Process reached max restarts (%d)", p.Config.Restart.RestartLimit())
 			p.mu.Unlock()
 			return
 		}
 
-		// Increase restart delay exponentially
-		p.restartDelay = time.Duration(float64(p.restartDelay) * 1.5)
-		if p.restartDelay > MaxRestartDelay {
-			p.restartDelay = MaxRestartDelay
-		}
+		p.setStatus(Backoff)
+		delay := p.budget.next(now)
+		attempt := p.budget.count(now)
+		p.mu.Unlock()
 
-		if p.logger != nil {
-			p.logger(fmt.Sprintf("[INFO] Restarting process: %s in %v (attempt %d/%d)",
-				p.ID, p.restartDelay.Round(time.Millisecond), currentRestartCount+1, MaxRestarts))
-		}
+		p.log(logging.LevelInfo, "Restarting process in %v (attempt %s)",
+			delay.Round(time.Millisecond), p.attemptString(attempt))
 
 		select {
-		case <-p.quit:
+		case <-quit:
 			return
-		case <-time.After(p.restartDelay):
+		case <-time.After(delay):
 		}
+	}
+}
 
-		p.mu.Lock()
-		p.restartCount++
+// startProbes launches the liveness and readiness monitors for the current
+// run; they stop when ctx is cancelled. A liveness failure is delivered on
+// the returned channel.
+func (p *Process) startProbes(ctx context.Context) <-chan error {
+	failed := make(chan error, 1)
+
+	if pc := p.Config.Liveness; pc != nil {
+		mon, err := health.NewMonitor(*pc, true, func(healthy bool, err error) {
+			p.mu.Lock()
+			p.notifyHealth("liveness", healthy, err)
+			p.mu.Unlock()
+			if !healthy {
+				select {
+				case failed <- err:
+				default:
+				}
+			}
+		})
+		if err != nil {
+			p.log(logging.LevelWarn, "Liveness probe disabled: %v", err)
+		} else {
+			mon.OnFailure(func(error) { p.countProbeFailure("liveness") })
+			go mon.Run(ctx)
+		}
+	}
+
+	if pc := p.Config.Readiness; pc != nil {
+		mon, err := health.NewMonitor(*pc, false, func(healthy bool, err error) {
+			p.mu.Lock()
+			p.ready = healthy
+			p.notifyHealth("readiness", healthy, err)
+			p.mu.Unlock()
+			if healthy {
+				p.log(logging.LevelInfo, "Readiness probe succeeded, process is ready")
+			} else {
+				p.log(logging.LevelWarn, "Readiness probe failed: %v", err)
+			}
+		})
+		if err != nil {
+			p.log(logging.LevelWarn, "Readiness probe disabled: %v", err)
+		} else {
+			mon.OnFailure(func(error) { p.countProbeFailure("readiness") })
+			go mon.Run(ctx)
+		}
+	}
+
+	return failed
+}
+
+// markRunning completes the Starting→Running transition.
+func (p *Process) markRunning() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.Status != Starting {
+		return
+	}
+	p.setStatus(Running)
+	p.startRetries = 0
+	p.reportStart(nil)
+	if p.degraded {
+		p.notify(config.NotifyRecovered, "Process recovered")
+	}
+	if grace := p.Config.StartGrace(); grace > 0 {
+		p.log(logging.LevelInfo, "Process is running (up for %v)", grace)
+	}
+}
+
+// startFailed counts a failed start attempt and waits before the next one.
+// It returns false when the process should not be started again.
+func (p *Process) startFailed(quit <-chan struct{}) bool {
+	p.mu.Lock()
+	if !p.restart {
+		p.mu.Unlock()
+		return false
+	}
+
+	p.startRetries++
+	attempt := p.startRetries
+	maxRetries := p.Config.MaxStartRetries()
+	if attempt > maxRetries {
+		p.restart = false
+		p.exitError = fmt.Errorf("%w after %d attempts: %w", ErrStartFailed, attempt, p.exitError)
+		p.setStatus(Fatal)
+		p.reportStart(fmt.Errorf("process %s %w", p.ID, p.exitError))
+		p.notify(config.NotifyFatal, "Process gave up after %d failed start attempts", attempt)
 		p.mu.Unlock()
+		p.log(logging.LevelWarn, "Giving up after %d failed start attempts", attempt)
+		return false
+	}
+
+	p.setStatus(Backoff)
+	delay := p.budget.backoff()
+	p.mu.Unlock()
+
+	p.log(logging.LevelInfo, "Retrying start in %v (attempt %d/%d)", delay.Round(time.Millisecond), attempt, maxRetries)
+
+	select {
+	case <-quit:
+		return false
+	case <-time.After(delay):
+		return true
+	}
+}
+
+// reportStart hands the outcome of the first start to the Manager.Start
+// call waiting for it. It must be called with p.mu held.
+func (p *Process) reportStart(err error) {
+	if p.started != nil {
+		p.started <- err
+		p.started = nil
+	}
+}
+
+// attemptString formats a restart attempt against the restart budget.
+func (p *Process) attemptString(attempt int) string {
+	if p.Config.Restart.RestartLimit() < 0 {
+		return fmt.Sprintf("%d", attempt)
+	}
+	return fmt.Sprintf("%d/%d", attempt, p.Config.Restart.RestartLimit())
+}
+
+// expectedExit reports whether code is listed in ExitCodes. A process
+// killed by a signal (code -1) never exits as expected.
+func (p *Process) expectedExit(code int) bool {
+	return code >= 0 && slices.Contains(p.Config.ExitCodes, code)
+}
+
+// shouldRestart applies the configured restart policy to an exit code.
+func (p *Process) shouldRestart(code int) bool {
+	switch p.Config.Autorestart {
+	case config.RestartAlways:
+		return true
+	case config.RestartOnFailure:
+		return code != 0
+	case config.RestartUnexpected:
+		return !p.expectedExit(code)
+	default:
+		return false
+	}
+}
+
+// terminate sends the configured stop signal to the whole process group,
+// waits up to StopWait for the group to exit and escalates to SIGKILL.
+func (p *Process) terminate(cmd *exec.Cmd, exited <-chan error) {
+	pid := cmd.Process.Pid
+
+	sigName := p.Config.StopSignal
+	sig, err := ParseSignal(sigName)
+	if err != nil {
+		p.log(logging.LevelWarn, "%v, falling back to SIGKILL", err)
+		sig, sigName = syscall.SIGKILL, "SIGKILL"
+	}
+
+	p.log(logging.LevelInfo, "Stopping process (PID: %d) with %s, waiting up to %v", pid, sigName, p.Config.StopWait)
+	if err := signalGroup(cmd, sig); err != nil {
+		p.log(logging.LevelWarn, "Failed to send %s to process group %d: %v", sigName, pid, err)
+	}
+
+	deadline := time.NewTimer(p.Config.StopWait)
+	defer deadline.Stop()
+
+	select {
+	case <-exited:
+		// Лидер завершился, дожидаемся остальных участников группы
+		ticker := time.NewTicker(100 * time.Millisecond)
+		defer ticker.Stop()
+		for groupAlive(cmd) {
+			select {
+			case <-ticker.C:
+			case <-deadline.C:
+				p.log(logging.LevelWarn, "Process group %d still alive after %v, sending SIGKILL", pid, p.Config.StopWait)
+				if err := signalGroup(cmd, syscall.SIGKILL); err != nil {
+					p.log(logging.LevelWarn, "Failed to kill process group %d: %v", pid, err)
+				}
+				return
+			}
+		}
+		p.log(logging.LevelInfo, "Process (PID: %d) stopped gracefully", pid)
+
+	case <-deadline.C:
+		p.log(logging.LevelWarn, "Process (PID: %d) did not stop within %v, sending SIGKILL", pid, p.Config.StopWait)
+		if err := signalGroup(cmd, syscall.SIGKILL); err != nil {
+			p.log(logging.LevelWarn, "Failed to kill process group %d: %v", pid, err)
+		}
+		<-exited
+		p.log(logging.LevelInfo, "Process (PID: %d) killed", pid)
+	}
+}
+
+// reapGroup kills helpers left behind in the group after the leader exited
+// on its own, so a restart does not end up with duplicate workers.
+func (p *Process) reapGroup(cmd *exec.Cmd) {
+	if !groupAlive(cmd) {
+		return
+	}
+	p.log(logging.LevelWarn, "Killing leftover processes in group %d", cmd.Process.Pid)
+	if err := signalGroup(cmd, syscall.SIGKILL); err != nil {
+		p.log(logging.LevelWarn, "Failed to kill process group %d: %v", cmd.Process.Pid, err)
 	}
 }
 
-func (p *Process) log(message string) {
+// log emits a supervisor message about the process.
+func (p *Process) log(level logging.Level, format string, args ...any) {
 	if p.logger != nil {
-		p.logger(fmt.Sprintf("[%s] %s", p.ID, message))
+		p.logger.Write(logging.Record{
+			Level:   level,
+			Process: p.ID,
+			Message: fmt.Sprintf(format, args...),
+		})
 	}
 }
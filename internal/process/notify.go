@@ -0,0 +1,94 @@
+package process
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kolkov/gosv/internal/config"
+)
+
+// Notification describes a lifecycle event worth telling someone about.
+// Probe and Healthy are set for health_changed only.
+type Notification struct {
+	Kind     config.NotifyEvent
+	Time     time.Time
+	Process  string
+	Program  string
+	Group    string
+	Status   Status
+	PID      int
+	ExitCode int
+	Error    error
+	Restarts int
+	Probe    string
+	Healthy  bool
+	Message  string
+}
+
+// Notifier delivers notifications. Notify is called from the process's
+// own goroutines and must not block.
+type Notifier interface {
+	Notify(n Notification)
+}
+
+// SetNotifier sets the notifier for processes added afterwards.
+func (m *Manager) SetNotifier(n Notifier) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.notifier = n
+}
+
+// notify reports a failed, fatal or recovered process. It must be called
+// with p.mu held.
+func (p *Process) notify(kind config.NotifyEvent, format string, args ...any) {
+	switch kind {
+	case config.NotifyFailed, config.NotifyFatal:
+		p.degraded = true
+	case config.NotifyRecovered:
+		p.degraded = false
+	}
+
+	n := p.notification(kind, fmt.Sprintf(format, args...))
+	if kind != config.NotifyRecovered {
+		n.ExitCode = p.exitCode
+		n.Error = p.exitError
+	}
+	p.send(n)
+}
+
+// notifyHealth reports a probe result change. It must be called with p.mu
+// held.
+func (p *Process) notifyHealth(probe string, healthy bool, err error) {
+	result := "failed"
+	if healthy {
+		result = "succeeded"
+	}
+	n := p.notification(config.NotifyHealthChanged, fmt.Sprintf("%s probe %s", probe, result))
+	n.Probe = probe
+	n.Healthy = healthy
+	n.Error = err
+	p.send(n)
+}
+
+func (p *Process) notification(kind config.NotifyEvent, message string) Notification {
+	n := Notification{
+		Kind:     kind,
+		Time:     time.Now(),
+		Process:  p.ID,
+		Program:  p.Config.Program,
+		Group:    p.Config.Group,
+		Status:   p.Status,
+		Restarts: p.budget.count(time.Now()),
+		Message:  message,
+	}
+	if p.Cmd != nil && p.Cmd.Process != nil {
+		n.PID = p.Cmd.Process.Pid
+	}
+	return n
+}
+
+func (p *Process) send(n Notification) {
+	if p.notifier != nil {
+		p.notifier.Notify(n)
+	}
+}
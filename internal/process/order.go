@@ -0,0 +1,247 @@
+package process
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/kolkov/gosv/internal/config"
+	"github.com/kolkov/gosv/internal/logging"
+)
+
+// startTask tracks one process during startSet.
+type startTask struct {
+	done chan struct{}
+	err  error
+}
+
+// StartAll starts every autostart process in dependency order.
+func (m *Manager) StartAll() error {
+	var autostart []*Process
+	for _, p := range m.ordered() {
+		if p.Config.Autostart {
+			autostart = append(autostart, p)
+		}
+	}
+	return m.startSet(autostart)
+}
+
+// StopAll stops every active process in reverse dependency order. It
+// returns once all processes are down, so the supervisor never leaves
+// before its children.
+func (m *Manager) StopAll() {
+	m.stopSet(m.ordered())
+}
+
+// startSet starts procs, given in start order. A process is started once
+// all of its dependencies have reached their conditions; processes that do
+// not depend on each other start concurrently and processes that are
+// already active are left alone. startSet waits until each process is
+// running or has failed to start.
+func (m *Manager) startSet(procs []*Process) error {
+	tasks := make(map[string]*startTask, len(procs))
+	for _, p := range procs {
+		tasks[p.ID] = &startTask{done: make(chan struct{})}
+	}
+
+	for _, p := range procs {
+		t := tasks[p.ID]
+		go func() {
+			defer close(t.done)
+			for _, dep := range p.Config.DependsOn {
+				if err := m.awaitDependency(dep, tasks); err != nil {
+					t.err = fmt.Errorf("process %s: %w", p.ID, err)
+					return
+				}
+			}
+
+			p.mu.Lock()
+			active := p.active()
+			p.mu.Unlock()
+			if !active {
+				t.err = m.start(p)
+			}
+		}()
+	}
+
+	var errs []error
+	for _, p := range procs {
+		t := tasks[p.ID]
+		<-t.done
+		if t.err != nil {
+			errs = append(errs, t.err)
+			m.log(logging.LevelError, "Failed to start process %s: %v", p.ID, t.err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// stopSet stops the active processes among procs, given in start order. A
+// process is stopped only after everything in procs that depends on it has
+// exited; independent processes stop concurrently.
+func (m *Manager) stopSet(procs []*Process) {
+	inSet := make(map[string]bool, len(procs))
+	for _, p := range procs {
+		inSet[p.ID] = true
+	}
+
+	dependents := make(map[string][]string)
+	done := make(map[string]chan struct{}, len(procs))
+	for _, p := range procs {
+		done[p.ID] = make(chan struct{})
+		for _, dep := range m.dependencies(p) {
+			if inSet[dep.ID] {
+				dependents[dep.ID] = append(dependents[dep.ID], p.ID)
+			}
+		}
+	}
+
+	for i := len(procs) - 1; i >= 0; i-- {
+		p := procs[i]
+		go func() {
+			defer close(done[p.ID])
+			for _, d := range dependents[p.ID] {
+				<-done[d]
+			}
+
+			p.mu.Lock()
+			if !p.active() {
+				p.mu.Unlock()
+				return
+			}
+			stopped := p.requestStop()
+			p.mu.Unlock()
+			<-stopped
+		}()
+	}
+
+	for _, p := range procs {
+		<-done[p.ID]
+	}
+}
+
+// ordered returns the processes sorted by config.StartOrder. If the
+// configs do not form a valid graph it falls back to name order.
+func (m *Manager) ordered() []*Process {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.orderedLocked()
+}
+
+func (m *Manager) orderedLocked() []*Process {
+	configs := make([]config.ProcessConfig, 0, len(m.processes))
+	for _, p := range m.processes {
+		configs = append(configs, p.Config)
+	}
+
+	names, err := config.StartOrder(configs)
+	if err != nil {
+		m.log(logging.LevelWarn, "%v, ignoring dependencies", err)
+		names = names[:0]
+		for _, c := range configs {
+			names = append(names, c.Name)
+		}
+		sort.Strings(names)
+	}
+
+	order := make([]*Process, 0, len(names))
+	for _, name := range names {
+		order = append(order, m.processes[name])
+	}
+	return order
+}
+
+// markOneShotsLocked flags the processes that some dependent waits on
+// with completed_successfully. It must be called with m.mu held.
+func (m *Manager) markOneShotsLocked() {
+	oneShot := make(map[string]bool)
+	for _, p := range m.processes {
+		for _, dep := range p.Config.DependsOn {
+			if dep.Condition != config.ConditionCompleted {
+				continue
+			}
+			ids, ok := m.programs[dep.Name]
+			if !ok {
+				ids = []string{dep.Name}
+			}
+			for _, id := range ids {
+				oneShot[id] = true
+			}
+		}
+	}
+	for id, p := range m.processes {
+		p.mu.Lock()
+		p.oneShot = oneShot[id]
+		p.mu.Unlock()
+	}
+}
+
+// dependencies returns the processes p depends on, with programs expanded
+// into their instances.
+func (m *Manager) dependencies(p *Process) []*Process {
+	var deps []*Process
+	for _, dep := range p.Config.DependsOn {
+		procs, _ := m.resolve(dep.Name)
+		deps = append(deps, procs...)
+	}
+	return deps
+}
+
+// awaitDependency blocks until dep, or every instance of it, reaches its
+// condition.
+func (m *Manager) awaitDependency(dep config.Dependency, tasks map[string]*startTask) error {
+	procs, err := m.resolve(dep.Name)
+	if err != nil {
+		return fmt.Errorf("dependency not found: %s", dep.Name)
+	}
+	for _, p := range procs {
+		if err := awaitCondition(p, dep.Condition, tasks[p.ID]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// awaitCondition waits for the StartAll task of p, if any, and then polls
+// p until it meets cond. It fails as soon as p is no longer active without
+// having met it.
+func awaitCondition(p *Process, cond config.DependencyCondition, t *startTask) error {
+	if t != nil {
+		<-t.done
+		if t.err != nil && cond != config.ConditionCompleted {
+			return fmt.Errorf("dependency %s failed to start: %w", p.ID, t.err)
+		}
+	}
+
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		p.mu.Lock()
+		met := p.satisfies(cond)
+		status, active := p.Status, p.active()
+		p.mu.Unlock()
+
+		if met {
+			return nil
+		}
+		if !active {
+			return fmt.Errorf("dependency %s is %s, condition %q not met", p.ID, status, cond)
+		}
+		<-ticker.C
+	}
+}
+
+// satisfies reports whether the process meets a dependency condition.
+// It must be called with p.mu held.
+func (p *Process) satisfies(cond config.DependencyCondition) bool {
+	switch cond {
+	case config.ConditionHealthy:
+		return p.Status == Running && (p.ready || p.Config.Readiness == nil)
+	case config.ConditionCompleted:
+		return p.completed
+	default:
+		return p.Status == Running
+	}
+}
@@ -0,0 +1,180 @@
+//go:build unix
+
+package process
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kolkov/gosv/internal/config"
+)
+
+func loadConfig(t *testing.T, data string) *config.Config {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "gosv.yaml")
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+func newTestManager(cfg *config.Config) *Manager {
+	m := NewManager(nil, nil)
+	for _, pc := range cfg.Processes {
+		m.AddProcess(pc)
+	}
+	return m
+}
+
+// api only starts if migrate has already written its marker, and StopAll
+// stops api before db. start_secs of api gives the shells time to set
+// their traps.
+func TestStartStopAllOrder(t *testing.T) {
+	dir := t.TempDir()
+	cfg := loadConfig(t, `
+processes:
+  - name: db
+    command: sh
+    args: ["-c", "trap 'echo db >> stops; exit 0' TERM; while :; do sleep 0.05; done"]
+    directory: `+dir+`
+    autostart: true
+    start_secs: 0
+  - name: migrate
+    command: sh
+    args: ["-c", "touch migrated"]
+    directory: `+dir+`
+    autostart: true
+    start_secs: 0
+    depends_on: [db]
+  - name: api
+    command: sh
+    args: ["-c", "test -f migrated || exit 1; trap 'echo api >> stops; exit 0' TERM; while :; do sleep 0.05; done"]
+    directory: `+dir+`
+    autostart: true
+    start_secs: 1
+    start_retries: 0
+    depends_on:
+      - db
+      - name: migrate
+        condition: completed_successfully
+`)
+	m := newTestManager(cfg)
+
+	if err := m.StartAll(); err != nil {
+		m.StopAll()
+		t.Fatalf("StartAll: %v", err)
+	}
+	if got := m.Status()["api"].Status; got != Running {
+		t.Errorf("api is %s, want %s", got, Running)
+	}
+
+	m.StopAll()
+	stops, err := os.ReadFile(filepath.Join(dir, "stops"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Fields(string(stops)); strings.Join(got, " ") != "api db" {
+		t.Errorf("stopped in order %v, want [api db]", got)
+	}
+}
+
+// A dependent of a process that fails to start is not started at all.
+func TestStartAllFailedDependency(t *testing.T) {
+	cfg := loadConfig(t, `
+processes:
+  - name: db
+    command: sh
+    args: ["-c", "exit 3"]
+    autostart: true
+    start_retries: 0
+  - name: api
+    command: sleep
+    args: ["30"]
+    autostart: true
+    depends_on: [db]
+`)
+	m := newTestManager(cfg)
+	defer m.StopAll()
+
+	if err := m.StartAll(); err == nil || !strings.Contains(err.Error(), "process db failed to start") {
+		t.Fatalf("StartAll: %v, want db's start failure", err)
+	}
+	if got := m.Status()["api"].Status; got != Stopped {
+		t.Errorf("api is %s, want %s", got, Stopped)
+	}
+}
+
+// A migration that finishes within start_secs has completed, not failed
+// to start, and its dependent starts after it.
+func TestStartAllCompletedDependency(t *testing.T) {
+	for _, policy := range []string{"never", "on-failure", "always"} {
+		t.Run(policy, func(t *testing.T) {
+			cfg := loadConfig(t, `
+processes:
+  - name: migrate
+    command: sh
+    args: ["-c", "sleep 0.01"]
+    autostart: true
+    autorestart: `+policy+`
+  - name: api
+    command: sleep
+    args: ["30"]
+    autostart: true
+    depends_on:
+      - name: migrate
+        condition: completed_successfully
+`)
+			m := newTestManager(cfg)
+			defer m.StopAll()
+
+			if err := m.StartAll(); err != nil {
+				t.Fatalf("StartAll: %v", err)
+			}
+			statuses := m.Status()
+			if got := statuses["api"].Status; got != Running {
+				t.Errorf("api is %s, want %s", got, Running)
+			}
+			migrate := statuses["migrate"]
+			if n := migrate.Stats.Exits[ExitStartFailed]; n != 0 {
+				t.Errorf("migrate counted %d failed starts", n)
+			}
+			if policy != "always" {
+				if migrate.Status != Exited || migrate.Stats.Starts != 1 {
+					t.Errorf("migrate is %s after %d starts, want exited after 1", migrate.Status, migrate.Stats.Starts)
+				}
+			}
+		})
+	}
+}
+
+// Without a completed_successfully dependent an early exit is still a
+// failed start.
+func TestStartEarlyExitFails(t *testing.T) {
+	cfg := loadConfig(t, `
+processes:
+  - name: quick
+    command: sh
+    args: ["-c", "sleep 0.01"]
+    start_retries: 1
+`)
+	m := newTestManager(cfg)
+
+	err := m.Start("quick")
+	if !errors.Is(err, ErrStartFailed) {
+		t.Fatalf("Start: %v, want %v", err, ErrStartFailed)
+	}
+	if !strings.Contains(err.Error(), "exit status 0") {
+		t.Errorf("Start: %v, want the exit status", err)
+	}
+	info := m.Status()["quick"]
+	if info.Status != Fatal || info.Stats.Starts != 2 {
+		t.Errorf("quick is %s after %d starts, want fatal after 2", info.Status, info.Stats.Starts)
+	}
+}
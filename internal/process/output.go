@@ -0,0 +1,156 @@
+package process
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"os/exec"
+
+	"github.com/kolkov/gosv/internal/config"
+	"github.com/kolkov/gosv/internal/logfile"
+	"github.com/kolkov/gosv/internal/logging"
+)
+
+// maxLineSize limits a single line of child output; longer lines are
+// split.
+const maxLineSize = 64 * 1024
+
+// ProtocolHandler takes over stdin and stdout of processes that talk to
+// the supervisor instead of producing regular output, i.e. event
+// listeners. Serve returns once stdout is closed; stdin is closed after
+// that.
+type ProtocolHandler interface {
+	Serve(cfg config.ProcessConfig, pid int, stdin io.Writer, stdout io.Reader)
+}
+
+// outputPipes carries the child's stdout and stderr, and its stdin for
+// event listeners. Unlike cmd.StdoutPipe, cmd.Wait does not close the
+// parent's ends, so the readers always see the output up to the end even
+// when the child exits first.
+type outputPipes struct {
+	stdout, stderr *os.File // stderr is nil with redirect_stderr
+	stdin          *os.File // nil unless the process is an event listener
+	childEnds      []*os.File
+}
+
+// openPipes creates the pipes and attaches the child's ends to cmd.
+func (p *Process) openPipes(cmd *exec.Cmd) (*outputPipes, error) {
+	o := &outputPipes{}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	o.stdout, o.childEnds = r, append(o.childEnds, w)
+	cmd.Stdout, cmd.Stderr = w, w
+
+	if !p.Config.RedirectStderr {
+		r, w, err := os.Pipe()
+		if err != nil {
+			o.close()
+			return nil, err
+		}
+		o.stderr, o.childEnds = r, append(o.childEnds, w)
+		cmd.Stderr = w
+	}
+
+	if p.listener() {
+		r, w, err := os.Pipe()
+		if err != nil {
+			o.close()
+			return nil, err
+		}
+		o.stdin, o.childEnds = w, append(o.childEnds, r)
+		cmd.Stdin = r
+	}
+	return o, nil
+}
+
+// listener reports whether stdin and stdout are handed to the protocol
+// handler.
+func (p *Process) listener() bool {
+	return p.Config.EventListener != nil && p.protocol != nil
+}
+
+// closeChildEnds drops the parent's copies of the child's ends; it is
+// called once cmd.Start has returned, so the readers get EOF when the
+// child and its descendants are gone.
+func (o *outputPipes) closeChildEnds() {
+	for _, f := range o.childEnds {
+		f.Close()
+	}
+	o.childEnds = nil
+}
+
+func (o *outputPipes) close() {
+	o.closeChildEnds()
+	o.stdout.Close()
+	if o.stderr != nil {
+		o.stderr.Close()
+	}
+	if o.stdin != nil {
+		o.stdin.Close()
+	}
+}
+
+// readOutput starts the goroutines that pass every line of output to the
+// logger and to the configured log files. An event listener's stdout goes
+// to the protocol handler instead.
+func (p *Process) readOutput(o *outputPipes, pid int) {
+	if o.stdin != nil {
+		go func() {
+			defer o.stdin.Close()
+			defer o.stdout.Close()
+			p.protocol.Serve(p.Config, pid, o.stdin, o.stdout)
+		}()
+	} else {
+		go p.readStream(o.stdout, pid, p.Config.StdoutLogfile, logging.StreamStdout)
+	}
+	if o.stderr != nil {
+		go p.readStream(o.stderr, pid, p.Config.StderrLogfile, logging.StreamStderr)
+	}
+}
+
+func (p *Process) readStream(r *os.File, pid int, path string, stream logging.Stream) {
+	defer r.Close()
+
+	var file *logfile.Writer
+	if path != "" {
+		w, err := logfile.Open(path, p.Config.LogRotate.Options())
+		if err != nil {
+			p.log(logging.LevelWarn, "Cannot open log file: %v", err)
+		} else {
+			defer w.Close()
+			file = w
+		}
+	}
+
+	writeFailed := false
+	reader := bufio.NewReaderSize(r, maxLineSize)
+	for {
+		chunk, _, err := reader.ReadLine()
+		if err != nil {
+			// io.EOF: все владельцы пайпа завершились
+			return
+		}
+		line := string(chunk)
+		if file != nil {
+			// Об ошибке записи сообщаем один раз, а не на каждую строку
+			if err := file.WriteLine(line); err != nil && !writeFailed {
+				p.log(logging.LevelWarn, "Writing log file failed: %v", err)
+				writeFailed = true
+			} else if err == nil {
+				writeFailed = false
+			}
+		}
+		if p.logger != nil {
+			p.logger.Write(logging.Record{
+				Level:   logging.LevelInfo,
+				Process: p.ID,
+				PID:     pid,
+				Stream:  stream,
+				Message: line,
+			})
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package process
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/kolkov/gosv/internal/config"
+	"github.com/kolkov/gosv/internal/logging"
+)
+
+// Apply brings the manager in line with cfg, as computed by config.Diff:
+// removed programs are stopped and dropped, updated ones are replaced and
+// restarted if they were active, and added ones are started if they have
+// autostart set. Unchanged processes keep running; only their group
+// follows cfg.
+func (m *Manager) Apply(cfg *config.Config, changes config.ChangeSet) error {
+	programs := make(map[string]config.ProcessConfig, len(cfg.Processes))
+	for _, pc := range cfg.Processes {
+		programs[pc.Name] = pc
+	}
+	replaced := slices.Concat(changes.Removed, changes.Updated)
+
+	// Изменённые программы после замены запускаем, только если они работали
+	active := make(map[string]bool)
+	m.mu.RLock()
+	var ids []string
+	for _, name := range replaced {
+		for _, p := range m.programLocked(name) {
+			p.mu.Lock()
+			if p.active() {
+				active[name] = true
+			}
+			p.mu.Unlock()
+			ids = append(ids, p.ID)
+		}
+	}
+	stop := m.subsetLocked(ids)
+	m.mu.RUnlock()
+
+	m.stopSet(stop)
+
+	m.mu.Lock()
+	for _, name := range replaced {
+		m.removeLocked(name)
+	}
+	var errs []error
+	var start []string
+	for _, name := range slices.Concat(changes.Updated, changes.Added) {
+		pc := programs[name]
+		names, err := m.addLocked(pc)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("process %s: %w", name, err))
+			continue
+		}
+		if active[name] || (pc.Autostart && slices.Contains(changes.Added, name)) {
+			start = append(start, names...)
+		}
+	}
+	m.regroupLocked(programs)
+	m.markOneShotsLocked()
+	procs := m.subsetLocked(start)
+	m.mu.Unlock()
+
+	for _, name := range changes.Removed {
+		m.log(logging.LevelInfo, "Removed process %s", name)
+	}
+	for _, name := range changes.Updated {
+		m.log(logging.LevelInfo, "Updated process %s", name)
+	}
+	for _, name := range changes.Added {
+		m.log(logging.LevelInfo, "Added process %s", name)
+	}
+
+	errs = append(errs, m.startSet(procs))
+	return errors.Join(errs...)
+}
+
+// programLocked returns the instances of a program, or the process itself
+// if it has only one. It must be called with m.mu held.
+func (m *Manager) programLocked(name string) []*Process {
+	if ids, ok := m.programs[name]; ok {
+		return m.subsetLocked(ids)
+	}
+	if p, ok := m.processes[name]; ok {
+		return []*Process{p}
+	}
+	return nil
+}
+
+// removeLocked drops the instances of a program. It must be called with
+// m.mu held and the instances stopped.
+func (m *Manager) removeLocked(name string) {
+	for _, p := range m.programLocked(name) {
+		delete(m.processes, p.ID)
+	}
+	delete(m.programs, name)
+}
+
+// regroupLocked moves every process into the group its program has in
+// programs and rebuilds the group index. It must be called with m.mu held.
+func (m *Manager) regroupLocked(programs map[string]config.ProcessConfig) {
+	m.groups = make(map[string][]string)
+	for id, p := range m.processes {
+		group := programs[p.Config.Program].Group
+		p.mu.Lock()
+		p.Config.Group = group
+		p.mu.Unlock()
+		if group != "" {
+			m.groups[group] = append(m.groups[group], id)
+		}
+	}
+}
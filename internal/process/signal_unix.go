@@ -0,0 +1,42 @@
+//go:build unix
+
+package process
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"syscall"
+)
+
+var stopSignals = map[string]syscall.Signal{
+	"TERM": syscall.SIGTERM,
+	"INT":  syscall.SIGINT,
+	"QUIT": syscall.SIGQUIT,
+	"HUP":  syscall.SIGHUP,
+	"USR1": syscall.SIGUSR1,
+	"USR2": syscall.SIGUSR2,
+	"KILL": syscall.SIGKILL,
+}
+
+// ParseSignal converts a signal name such as "TERM" or "SIGTERM" into a
+// signal value.
+func ParseSignal(name string) (syscall.Signal, error) {
+	sig, ok := stopSignals[strings.TrimPrefix(strings.ToUpper(name), "SIG")]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrInvalidSignal, name)
+	}
+	return sig, nil
+}
+
+// signalGroup delivers sig to every process in the child's process group.
+func signalGroup(cmd *exec.Cmd, sig syscall.Signal) error {
+	return syscall.Kill(-cmd.Process.Pid, sig)
+}
+
+// groupAlive reports whether any process is left in the child's group.
+func groupAlive(cmd *exec.Cmd) bool {
+	err := syscall.Kill(-cmd.Process.Pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
@@ -0,0 +1,55 @@
+//go:build windows
+
+package process
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"syscall"
+)
+
+var (
+	kernel32                     = syscall.NewLazyDLL("kernel32.dll")
+	procGenerateConsoleCtrlEvent = kernel32.NewProc("GenerateConsoleCtrlEvent")
+)
+
+const ctrlBreakEvent = 1
+
+var stopSignals = map[string]syscall.Signal{
+	"TERM": syscall.SIGTERM,
+	"INT":  syscall.SIGINT,
+	"QUIT": syscall.SIGQUIT,
+	"HUP":  syscall.SIGHUP,
+	"KILL": syscall.SIGKILL,
+}
+
+// ParseSignal converts a signal name such as "TERM" or "SIGTERM" into a
+// signal value. SIGUSR1 and SIGUSR2 do not exist on Windows.
+func ParseSignal(name string) (syscall.Signal, error) {
+	sig, ok := stopSignals[strings.TrimPrefix(strings.ToUpper(name), "SIG")]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrInvalidSignal, name)
+	}
+	return sig, nil
+}
+
+// signalGroup has no real signals to work with on Windows: SIGKILL
+// terminates the process, anything else becomes a CTRL_BREAK_EVENT sent
+// to the console process group created in configureCmd.
+func signalGroup(cmd *exec.Cmd, sig syscall.Signal) error {
+	if sig == syscall.SIGKILL {
+		return cmd.Process.Kill()
+	}
+	r, _, err := procGenerateConsoleCtrlEvent.Call(ctrlBreakEvent, uintptr(cmd.Process.Pid))
+	if r == 0 {
+		return err
+	}
+	return nil
+}
+
+// groupAlive cannot inspect console process groups, so once the leader has
+// exited the group is considered gone.
+func groupAlive(cmd *exec.Cmd) bool {
+	return false
+}
@@ -0,0 +1,50 @@
+package process
+
+import "maps"
+
+// ExitReason says why a process exited, for the exit counters.
+type ExitReason string
+
+const (
+	ExitExpected   ExitReason = "expected"   // код выхода из exit_codes
+	ExitUnexpected ExitReason = "unexpected" // любой другой код
+	// ExitStartFailed means the process exited within start_secs.
+	ExitStartFailed ExitReason = "start_failed"
+	// ExitLiveness means the process was killed after its liveness
+	// probe failed.
+	ExitLiveness ExitReason = "liveness"
+	// ExitStopped means the process was stopped by the supervisor.
+	ExitStopped ExitReason = "stopped"
+)
+
+// Stats counts lifecycle events of a process since it was added; they
+// start from zero when a reload replaces the process.
+type Stats struct {
+	Starts int
+	Exits  map[ExitReason]int
+	// ProbeFailures counts failed checks by probe: liveness or readiness.
+	ProbeFailures map[string]int
+}
+
+func (s Stats) clone() Stats {
+	s.Exits = maps.Clone(s.Exits)
+	s.ProbeFailures = maps.Clone(s.ProbeFailures)
+	return s
+}
+
+// countExit must be called with p.mu held.
+func (p *Process) countExit(reason ExitReason) {
+	if p.stats.Exits == nil {
+		p.stats.Exits = make(map[ExitReason]int)
+	}
+	p.stats.Exits[reason]++
+}
+
+func (p *Process) countProbeFailure(probe string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.stats.ProbeFailures == nil {
+		p.stats.ProbeFailures = make(map[string]int)
+	}
+	p.stats.ProbeFailures[probe]++
+}
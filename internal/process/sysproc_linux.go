@@ -0,0 +1,20 @@
+//go:build linux
+
+package process
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// configureCmd puts the child into its own process group so the whole tree
+// can be signalled at once, and asks the kernel to kill it if the
+// supervisor dies. Pdeathsig is bound to the spawning OS thread; the Go
+// runtime does not retire threads unless they are locked, so in practice
+// it fires only when the supervisor itself exits.
+func configureCmd(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Setpgid:   true,
+		Pdeathsig: syscall.SIGKILL,
+	}
+}
@@ -0,0 +1,16 @@
+//go:build unix && !linux
+
+package process
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// configureCmd puts the child into its own process group. Pdeathsig is
+// Linux-only, so on other Unix systems children survive a supervisor crash.
+func configureCmd(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Setpgid: true,
+	}
+}
@@ -0,0 +1,112 @@
+package process
+
+import "time"
+
+// Usage is the resource usage of a process together with all its
+// descendants. Descendants that detached and were reparented to init are
+// not counted.
+type Usage struct {
+	// CPUPercent is the CPU time used over the last sampling interval;
+	// 100 means one full core. It is 0 until the second sample.
+	CPUPercent float64
+	// CPUTime is the CPU time used by the processes that are alive now.
+	CPUTime time.Duration
+	RSS     uint64 // байты
+	Threads int
+	FDs     int
+	// ReadBytes and WriteBytes are the bytes the processes made the storage
+	// layer read and write.
+	ReadBytes  uint64
+	WriteBytes uint64
+	// Processes is the number of processes in the tree.
+	Processes int
+	// Time is when the sample was taken, zero if there is none.
+	Time time.Time
+}
+
+// Sampled reports whether the usage was measured.
+func (u Usage) Sampled() bool {
+	return !u.Time.IsZero()
+}
+
+// UsageSampler measures the usage of process trees. It remembers the
+// previous CPU times to compute CPUPercent, so the same sampler has to be
+// used for consecutive samples. It is not safe for concurrent use.
+type UsageSampler struct {
+	prev map[int]cpuSample // по PID корня дерева
+}
+
+type cpuSample struct {
+	cpu time.Duration
+	at  time.Time
+}
+
+func NewUsageSampler() *UsageSampler {
+	return &UsageSampler{prev: make(map[int]cpuSample)}
+}
+
+// Sample measures the trees rooted at pids. Processes that are gone, and
+// all processes on platforms where UsageSupported is false, are missing
+// from the result.
+func (s *UsageSampler) Sample(pids []int) map[int]Usage {
+	now := time.Now()
+	usage := sampleTrees(pids)
+	prev := s.prev
+	s.prev = make(map[int]cpuSample, len(usage))
+	for pid, u := range usage {
+		u.Time = now
+		// Время завершившихся потомков выпадает из суммы, тогда интервал пропускаем
+		if p, ok := prev[pid]; ok && u.CPUTime >= p.cpu && now.After(p.at) {
+			u.CPUPercent = 100 * float64(u.CPUTime-p.cpu) / float64(now.Sub(p.at))
+		}
+		s.prev[pid] = cpuSample{cpu: u.CPUTime, at: now}
+		usage[pid] = u
+	}
+	return usage
+}
+
+// SampleUsage measures the processes that are up and stores the result in
+// their status; other processes get an empty Usage.
+func (m *Manager) SampleUsage(s *UsageSampler) {
+	m.mu.RLock()
+	pids := make(map[*Process]int, len(m.processes))
+	for _, proc := range m.processes {
+		proc.mu.Lock()
+		if pid := proc.activePID(); pid > 0 {
+			pids[proc] = pid
+		} else {
+			proc.usage = Usage{}
+		}
+		proc.mu.Unlock()
+	}
+	m.mu.RUnlock()
+
+	roots := make([]int, 0, len(pids))
+	for _, pid := range pids {
+		roots = append(roots, pid)
+	}
+	usage := s.Sample(roots)
+
+	for proc, pid := range pids {
+		proc.mu.Lock()
+		// Процесс мог перезапуститься, пока шёл замер
+		if proc.activePID() == pid {
+			proc.usage = usage[pid]
+		} else {
+			proc.usage = Usage{}
+		}
+		proc.mu.Unlock()
+	}
+}
+
+// activePID returns the PID of a process that is up, 0 otherwise. It must
+// be called with p.mu held.
+func (p *Process) activePID() int {
+	switch p.Status {
+	case Starting, Running, Stopping:
+		if p.Cmd != nil && p.Cmd.Process != nil {
+			return p.Cmd.Process.Pid
+		}
+	}
+	return 0
+}
@@ -0,0 +1,151 @@
+//go:build linux
+
+package process
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// UsageSupported reports whether resource usage can be sampled here.
+const UsageSupported = true
+
+// clockTicks is USER_HZ, the unit of CPU times in /proc/<pid>/stat. It is
+// 100 on every architecture Linux supports; the exact value needs sysconf
+// and with it cgo.
+const clockTicks = 100
+
+// procStat holds the fields of /proc/<pid>/stat used here.
+type procStat struct {
+	ppid int
+	cpu  uint64 // utime + stime в тиках
+}
+
+// sampleTrees sums the usage over each root and its descendants, found by
+// the parent PIDs of all processes in /proc.
+func sampleTrees(roots []int) map[int]Usage {
+	if len(roots) == 0 {
+		return nil
+	}
+	stats := readAllStats()
+	children := make(map[int][]int)
+	for pid, st := range stats {
+		children[st.ppid] = append(children[st.ppid], pid)
+	}
+
+	result := make(map[int]Usage, len(roots))
+	for _, root := range roots {
+		if _, ok := stats[root]; !ok {
+			continue
+		}
+		var u Usage
+		for queue := []int{root}; len(queue) > 0; queue = queue[1:] {
+			pid := queue[0]
+			queue = append(queue, children[pid]...)
+			u.Processes++
+			u.CPUTime += time.Duration(stats[pid].cpu) * time.Second / clockTicks
+			addStatus(&u, pid)
+			addIO(&u, pid)
+			u.FDs += countFDs(pid)
+		}
+		result[root] = u
+	}
+	return result
+}
+
+func readAllStats() map[int]procStat {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil
+	}
+	stats := make(map[int]procStat, len(entries))
+	for _, e := range entries {
+		pid, err := strconv.Atoi(e.Name())
+		if err != nil {
+			continue
+		}
+		if st, ok := readStat(pid); ok {
+			stats[pid] = st
+		}
+	}
+	return stats
+}
+
+func readStat(pid int) (procStat, bool) {
+	data, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+	if err != nil {
+		return procStat{}, false
+	}
+	// Имя команды в скобках может содержать пробелы и скобки
+	i := bytes.LastIndexByte(data, ')')
+	if i < 0 {
+		return procStat{}, false
+	}
+	// После имени: state ppid ... utime(14) stime(15), считая от pid
+	f := strings.Fields(string(data[i+1:]))
+	if len(f) < 13 {
+		return procStat{}, false
+	}
+	ppid, _ := strconv.Atoi(f[1])
+	utime, _ := strconv.ParseUint(f[11], 10, 64)
+	stime, _ := strconv.ParseUint(f[12], 10, 64)
+	return procStat{ppid: ppid, cpu: utime + stime}, true
+}
+
+// addStatus adds the resident memory and threads from /proc/<pid>/status.
+func addStatus(u *Usage, pid int) {
+	readFields("/proc/"+strconv.Itoa(pid)+"/status", func(key, value string) {
+		switch key {
+		case "VmRSS":
+			kb, _ := strconv.ParseUint(strings.TrimSuffix(value, " kB"), 10, 64)
+			u.RSS += kb * 1024
+		case "Threads":
+			n, _ := strconv.Atoi(value)
+			u.Threads += n
+		}
+	})
+}
+
+// addIO adds the storage I/O from /proc/<pid>/io. The file is readable
+// only for processes of the same user, unless gosv runs as root.
+func addIO(u *Usage, pid int) {
+	readFields("/proc/"+strconv.Itoa(pid)+"/io", func(key, value string) {
+		switch key {
+		case "read_bytes":
+			n, _ := strconv.ParseUint(value, 10, 64)
+			u.ReadBytes += n
+		case "write_bytes":
+			n, _ := strconv.ParseUint(value, 10, 64)
+			u.WriteBytes += n
+		}
+	})
+}
+
+// readFields calls f for every "key: value" line of a /proc file.
+func readFields(path string, f func(key, value string)) {
+	file, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+	sc := bufio.NewScanner(file)
+	for sc.Scan() {
+		if key, value, ok := strings.Cut(sc.Text(), ":"); ok {
+			f(key, strings.TrimSpace(value))
+		}
+	}
+}
+
+func countFDs(pid int) int {
+	dir, err := os.Open("/proc/" + strconv.Itoa(pid) + "/fd")
+	if err != nil {
+		return 0
+	}
+	defer dir.Close()
+	names, _ := dir.Readdirnames(-1)
+	return len(names)
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package process
+
+// UsageSupported reports whether resource usage can be sampled here.
+const UsageSupported = false
+
+func sampleTrees(roots []int) map[int]Usage {
+	return nil
+}
@@ -1,6 +1,11 @@
 package service
 
-import "github.com/kolkov/gosv/internal/supervisor"
+import (
+	"github.com/kolkov/gosv/internal/audit"
+	"github.com/kolkov/gosv/internal/config"
+	"github.com/kolkov/gosv/internal/logging"
+	"github.com/kolkov/gosv/internal/supervisor"
+)
 
 type supervisorAdapter struct {
 	*supervisor.Supervisor
@@ -18,10 +23,70 @@ func (s *supervisorAdapter) RestartProcess(name string) error {
 	return s.Supervisor.RestartProcess(name)
 }
 
+func (s *supervisorAdapter) SignalProcess(name, signal string) error {
+	return s.Supervisor.SignalProcess(name, signal)
+}
+
+func (s *supervisorAdapter) StartGroup(name string) error {
+	return s.Supervisor.StartGroup(name)
+}
+
+func (s *supervisorAdapter) StopGroup(name string) error {
+	return s.Supervisor.StopGroup(name)
+}
+
+func (s *supervisorAdapter) RestartGroup(name string) error {
+	return s.Supervisor.RestartGroup(name)
+}
+
 func (s *supervisorAdapter) Status() map[string]*supervisor.ProcessInfo {
 	return s.Supervisor.Status()
 }
 
+func (s *supervisorAdapter) Logs(f logging.Filter, limit int) []logging.Record {
+	return s.Supervisor.Logs(f, limit)
+}
+
+func (s *supervisorAdapter) SubscribeLogs(f logging.Filter) (<-chan logging.Record, func()) {
+	return s.Supervisor.SubscribeLogs(f)
+}
+
+func (s *supervisorAdapter) SubscribeEvents() (<-chan supervisor.Event, func()) {
+	return s.Supervisor.SubscribeEvents()
+}
+
+func (s *supervisorAdapter) ProcessNames(name string) ([]string, error) {
+	return s.Supervisor.ProcessNames(name)
+}
+
+func (s *supervisorAdapter) GroupMembers(group string) ([]string, error) {
+	return s.Supervisor.GroupMembers(group)
+}
+
+func (s *supervisorAdapter) Reload(dryRun bool) (config.ChangeSet, error) {
+	return s.Supervisor.Reload(dryRun)
+}
+
+func (s *supervisorAdapter) AddProcess(pc config.ProcessConfig, dryRun bool) (config.ChangeSet, error) {
+	return s.Supervisor.AddProcess(pc, dryRun)
+}
+
+func (s *supervisorAdapter) UpdateProcess(pc config.ProcessConfig, dryRun bool) (config.ChangeSet, error) {
+	return s.Supervisor.UpdateProcess(pc, dryRun)
+}
+
+func (s *supervisorAdapter) RemoveProcess(name string, dryRun bool) (config.ChangeSet, error) {
+	return s.Supervisor.RemoveProcess(name, dryRun)
+}
+
+func (s *supervisorAdapter) Audit(r audit.Record) {
+	s.Supervisor.Audit(r)
+}
+
+func (s *supervisorAdapter) AuditLog(f audit.Filter, limit int) ([]audit.Record, error) {
+	return s.Supervisor.AuditLog(f, limit)
+}
+
 // AsService преобразует Supervisor в SupervisorService
 func AsService(s *supervisor.Supervisor) SupervisorService {
 	return &supervisorAdapter{s}
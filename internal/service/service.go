@@ -1,10 +1,30 @@
 package service
 
-import "github.com/kolkov/gosv/internal/supervisor"
+import (
+	"github.com/kolkov/gosv/internal/audit"
+	"github.com/kolkov/gosv/internal/config"
+	"github.com/kolkov/gosv/internal/logging"
+	"github.com/kolkov/gosv/internal/supervisor"
+)
 
 type SupervisorService interface {
 	StartProcess(name string) error
 	StopProcess(name string) error
 	RestartProcess(name string) error
+	SignalProcess(name, signal string) error
+	StartGroup(name string) error
+	StopGroup(name string) error
+	RestartGroup(name string) error
 	Status() map[string]*supervisor.ProcessInfo
+	Logs(f logging.Filter, limit int) []logging.Record
+	SubscribeLogs(f logging.Filter) (<-chan logging.Record, func())
+	SubscribeEvents() (<-chan supervisor.Event, func())
+	ProcessNames(name string) ([]string, error)
+	GroupMembers(group string) ([]string, error)
+	Reload(dryRun bool) (config.ChangeSet, error)
+	AddProcess(pc config.ProcessConfig, dryRun bool) (config.ChangeSet, error)
+	UpdateProcess(pc config.ProcessConfig, dryRun bool) (config.ChangeSet, error)
+	RemoveProcess(name string, dryRun bool) (config.ChangeSet, error)
+	Audit(r audit.Record)
+	AuditLog(f audit.Filter, limit int) ([]audit.Record, error)
 }
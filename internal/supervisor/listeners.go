@@ -0,0 +1,161 @@
+package supervisor
+
+import (
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/kolkov/gosv/internal/config"
+	"github.com/kolkov/gosv/internal/eventlistener"
+	"github.com/kolkov/gosv/internal/logging"
+	"github.com/kolkov/gosv/internal/process"
+)
+
+// supervisordStates maps statuses onto supervisord's state names. Failed
+// has no counterpart there: it is an unexpected EXITED.
+var supervisordStates = map[process.Status]string{
+	process.Stopped:  "STOPPED",
+	process.Starting: "STARTING",
+	process.Running:  "RUNNING",
+	process.Backoff:  "BACKOFF",
+	process.Stopping: "STOPPING",
+	process.Exited:   "EXITED",
+	process.Failed:   "EXITED",
+	process.Fatal:    "FATAL",
+}
+
+// listenerBridge feeds status transitions and process output to the event
+// listener dispatcher and serves listener processes for the manager.
+type listenerBridge struct {
+	dispatcher *eventlistener.Dispatcher
+	output     chan logging.Record
+
+	mu     sync.RWMutex
+	groups map[string]string // процесс -> groupname в терминах supervisord
+	stdout map[string]bool   // процессы с stdout_events_enabled
+	stderr map[string]bool
+}
+
+func newListenerBridge(logger logging.Sink, events <-chan Event) *listenerBridge {
+	b := &listenerBridge{
+		dispatcher: eventlistener.NewDispatcher(logger),
+		output:     make(chan logging.Record, 256),
+	}
+	go func() {
+		for e := range events {
+			b.publishState(e)
+		}
+	}()
+	// Публикуем вне Pipeline.Write: диспетчер сам пишет в тот же лог
+	go func() {
+		for r := range b.output {
+			b.publishOutput(r)
+		}
+	}()
+	return b
+}
+
+// configure creates the pools of the configured listeners, drops those no
+// longer configured and refreshes the per-process settings.
+func (b *listenerBridge) configure(cfg *config.Config) {
+	groups := make(map[string]string)
+	stdout := make(map[string]bool)
+	stderr := make(map[string]bool)
+	pools := make(map[string]bool)
+	for _, pc := range cfg.Processes {
+		if el := pc.EventListener; el != nil {
+			b.dispatcher.SetPool(pc.Name, el.Events, el.BufferSize)
+			pools[pc.Name] = true
+		}
+		instances, err := pc.Instances()
+		if err != nil {
+			continue
+		}
+		for _, inst := range instances {
+			groups[inst.Name] = groupName(inst.Group, inst.Program)
+			stdout[inst.Name] = inst.StdoutEvents
+			stderr[inst.Name] = inst.StderrEvents
+		}
+	}
+	for _, name := range b.dispatcher.Pools() {
+		if !pools[name] {
+			b.dispatcher.RemovePool(name)
+		}
+	}
+
+	b.mu.Lock()
+	b.groups, b.stdout, b.stderr = groups, stdout, stderr
+	b.mu.Unlock()
+}
+
+// Serve implements process.ProtocolHandler.
+func (b *listenerBridge) Serve(cfg config.ProcessConfig, pid int, stdin io.Writer, stdout io.Reader) {
+	b.dispatcher.Serve(cfg.Program, cfg.Name, stdin, stdout)
+}
+
+// Write turns output lines of processes with stdout_events_enabled or
+// stderr_events_enabled into PROCESS_LOG events.
+func (b *listenerBridge) Write(r logging.Record) {
+	if r.Stream == logging.StreamSupervisor {
+		return
+	}
+	select {
+	case b.output <- r:
+	default:
+	}
+}
+
+func (b *listenerBridge) publishOutput(r logging.Record) {
+	var name string
+	b.mu.RLock()
+	switch {
+	case r.Stream == logging.StreamStdout && b.stdout[r.Process]:
+		name = eventlistener.ProcessLogStdout
+	case r.Stream == logging.StreamStderr && b.stderr[r.Process]:
+		name = eventlistener.ProcessLogStderr
+	}
+	group := b.groups[r.Process]
+	b.mu.RUnlock()
+
+	if name != "" {
+		b.dispatcher.Publish(name, eventlistener.LogPayload(r.Process, group, r.PID, r.Message+"\n"))
+	}
+}
+
+func (b *listenerBridge) publishState(e Event) {
+	to, ok := supervisordStates[e.To]
+	if !ok {
+		return
+	}
+	from, ok := supervisordStates[e.From]
+	if !ok {
+		from = "UNKNOWN"
+	}
+
+	// Поля, которые supervisord добавляет для каждого состояния
+	var extra []string
+	switch e.To {
+	case process.Starting, process.Backoff:
+		extra = append(extra, fmt.Sprintf("tries:%d", e.Tries))
+	case process.Running, process.Stopping, process.Stopped:
+		extra = append(extra, fmt.Sprintf("pid:%d", e.PID))
+	case process.Exited, process.Failed:
+		expected := 0
+		if e.To == process.Exited {
+			expected = 1
+		}
+		extra = append(extra, fmt.Sprintf("expected:%d", expected), fmt.Sprintf("pid:%d", e.PID))
+	}
+
+	payload := eventlistener.StatePayload(e.Process, groupName(e.Group, e.Program), from, extra...)
+	b.dispatcher.Publish("PROCESS_STATE_"+to, payload)
+}
+
+// groupName is the groupname reported to listeners: the process group, or
+// the program as in supervisord.
+func groupName(group, program string) string {
+	if group != "" {
+		return group
+	}
+	return program
+}
@@ -5,6 +5,8 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"reflect"
+	"sort"
 	"strings"
 	"sync"
 	"syscall"
@@ -12,7 +14,10 @@ import (
 
 	"github.com/fatih/color"
 	"github.com/gdamore/tcell/v2"
+	"github.com/kolkov/gosv/internal/audit"
 	"github.com/kolkov/gosv/internal/config"
+	"github.com/kolkov/gosv/internal/logging"
+	"github.com/kolkov/gosv/internal/notify"
 	"github.com/kolkov/gosv/internal/process"
 	"github.com/rivo/tview"
 )
@@ -20,44 +25,169 @@ import (
 // Вынесем ProcessInfo в отдельный файл или оставим здесь
 type ProcessInfo = process.ProcessInfo
 
+// Event is a process status transition.
+type Event = process.Event
+
 type Supervisor struct {
 	manager *process.Manager
 	config  *config.Config
-	logs    []string
-	logMu   sync.Mutex
+	logs    *logging.Pipeline
+	ring    *logging.ProcessRings // последние записи для TUI и API
+	events  *process.EventBus     // переживает перезагрузку конфигурации
+	// listeners раздаёт события процессам-слушателям
+	listeners *listenerBridge
+	notifier  *notify.Notifier
+	audit     *audit.Log // nil, если файл не удалось открыть
+	// auditFile - путь журнала аудита при запуске; s.config меняется при
+	// перезагрузке, а журнал нет
+	auditFile string
+	reloadMu  sync.Mutex // по одному изменению конфигурации за раз
+	// overrides - настройки командной строки поверх файла конфигурации
+	overrides func(*config.Config) error
 }
 
 func New(cfg *config.Config) *Supervisor {
-	// Создаем временный логгер
-	tempLogger := func(log string) {}
+	size := cfg.Logging.BufferSize
+	if size == 0 {
+		size = config.DefaultLogBufferSize
+	}
+	ring := logging.NewProcessRings(size)
+
+	s := &Supervisor{
+		config: cfg,
+		logs:   logging.NewPipeline(ring),
+		ring:   ring,
+		events: process.NewEventBus(),
+	}
+	s.openLogFile()
+	s.openAuditLog()
+
+	events, _ := s.events.Subscribe(256)
+	s.listeners = newListenerBridge(s.logs, events)
+	s.listeners.configure(cfg)
+	s.logs.Add(s.listeners)
+	s.notifier = notify.New(cfg.Notifications, s.logs)
+
+	s.manager = s.newManager(cfg)
+	if process.UsageSupported {
+		go s.sampleUsage()
+	}
+	return s
+}
 
-	manager := process.NewManager(tempLogger)
+// usageInterval is how often the resource usage of processes is sampled.
+const usageInterval = 5 * time.Second
+
+// sampleUsage refreshes the resource usage in the process status for the
+// lifetime of the supervisor.
+func (s *Supervisor) sampleUsage() {
+	sampler := process.NewUsageSampler()
+	ticker := time.NewTicker(usageInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		s.manager.SampleUsage(sampler)
+	}
+}
+
+// newManager creates a manager for the processes of cfg, wired to the
+// supervisor's log, events, listeners and notifications.
+func (s *Supervisor) newManager(cfg *config.Config) *process.Manager {
+	m := process.NewManager(s.logs, s.events)
+	m.SetProtocolHandler(s.listeners)
+	m.SetNotifier(s.notifier)
 	for _, pcfg := range cfg.Processes {
-		manager.AddProcess(pcfg)
+		m.AddProcess(pcfg)
 	}
+	return m
+}
 
-	return &Supervisor{
-		manager: manager,
-		config:  cfg,
-		logs:    make([]string, 0),
+// openLogFile attaches the log file from the logging section, if any.
+func (s *Supervisor) openLogFile() {
+	lc := s.config.Logging
+	if lc.File == "" {
+		return
 	}
+
+	level, err := logging.ParseLevel(lc.Level)
+	if err != nil {
+		s.Log(logging.LevelWarn, "%v, using info", err)
+	}
+	file, err := logging.OpenFile(lc.File, lc.Rotate.Options(), lc.Format == "json")
+	if err != nil {
+		s.Log(logging.LevelError, "Cannot open log file: %v", err)
+		return
+	}
+	s.logs.Add(logging.Filtered(file, logging.Filter{MinLevel: level}))
 }
 
-// Устанавливаем логгер для менеджера процессов
-func (s *Supervisor) SetLogger(logger func(string)) {
-	s.manager.SetLogger(logger)
+// openAuditLog opens the audit file from the audit section. Without it
+// actions are still carried out, but not recorded.
+func (s *Supervisor) openAuditLog() {
+	s.auditFile = s.config.Audit.File
+	log, err := audit.Open(s.auditFile)
+	if err != nil {
+		s.Log(logging.LevelError, "Cannot open audit log, control actions will not be recorded: %v", err)
+		return
+	}
+	s.audit = log
 }
 
-// Добавляем лог-сообщение с защитой от гонок
-func (s *Supervisor) AddLog(log string) {
-	s.logMu.Lock()
-	defer s.logMu.Unlock()
+// Audit records a control action.
+func (s *Supervisor) Audit(r audit.Record) {
+	if s.audit == nil {
+		return
+	}
+	if err := s.audit.Write(r); err != nil {
+		s.Log(logging.LevelError, "Cannot write audit record: %v", err)
+	}
+}
 
-	// Ограничиваем количество хранимых логов
-	if len(s.logs) > 1000 {
-		s.logs = s.logs[1:]
+// AuditLog returns the recorded actions matching f, oldest first; with
+// limit > 0 only the most recent limit records.
+func (s *Supervisor) AuditLog(f audit.Filter, limit int) ([]audit.Record, error) {
+	if s.audit == nil {
+		return nil, fmt.Errorf("audit log %s is not available", s.auditFile)
 	}
-	s.logs = append(s.logs, log)
+	return s.audit.Read(f, limit)
+}
+
+// AddSink attaches a sink to the log pipeline and returns a function that
+// detaches it again.
+func (s *Supervisor) AddSink(sink logging.Sink) (remove func()) {
+	return s.logs.Add(sink)
+}
+
+// Log emits a supervisor message.
+func (s *Supervisor) Log(level logging.Level, format string, args ...any) {
+	s.logs.Write(logging.Record{Level: level, Message: fmt.Sprintf(format, args...)})
+}
+
+// Logs returns the buffered records matching f, oldest first; with
+// limit > 0 only the most recent limit records.
+func (s *Supervisor) Logs(f logging.Filter, limit int) []logging.Record {
+	return s.ring.Records(f, limit)
+}
+
+// SubscribeLogs delivers new records matching f until cancel is called.
+func (s *Supervisor) SubscribeLogs(f logging.Filter) (records <-chan logging.Record, cancel func()) {
+	return s.logs.Subscribe(f, 256)
+}
+
+// SubscribeEvents delivers process status transitions until cancel is
+// called.
+func (s *Supervisor) SubscribeEvents() (events <-chan Event, cancel func()) {
+	return s.events.Subscribe(256)
+}
+
+// ProcessNames returns the processes addressed by name: a process,
+// instance, program or "group:*".
+func (s *Supervisor) ProcessNames(name string) ([]string, error) {
+	return s.manager.Members(name)
+}
+
+// GroupMembers returns the processes of a group.
+func (s *Supervisor) GroupMembers(group string) ([]string, error) {
+	return s.manager.GroupMembers(group)
 }
 
 func (s *Supervisor) StartAll() error {
@@ -77,21 +207,151 @@ func (s *Supervisor) StopProcess(name string) error {
 }
 
 func (s *Supervisor) RestartProcess(name string) error {
-	if err := s.manager.Stop(name); err != nil {
-		return err
+	return s.manager.Restart(name)
+}
+
+func (s *Supervisor) SignalProcess(name, signal string) error {
+	return s.manager.Signal(name, signal)
+}
+
+func (s *Supervisor) StartGroup(name string) error {
+	return s.manager.StartGroup(name)
+}
+
+func (s *Supervisor) StopGroup(name string) error {
+	return s.manager.StopGroup(name)
+}
+
+func (s *Supervisor) RestartGroup(name string) error {
+	return s.manager.RestartGroup(name)
+}
+
+// SetOverrides sets the command line settings that take precedence over
+// the config file. They are applied again to every reloaded config, so
+// that it describes the listeners actually running.
+func (s *Supervisor) SetOverrides(f func(*config.Config) error) {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+	s.overrides = f
+}
+
+// ReloadConfig applies newCfg and returns what changed. Only programs
+// whose settings changed are restarted; with dryRun nothing is applied.
+func (s *Supervisor) ReloadConfig(newCfg *config.Config, dryRun bool) (config.ChangeSet, error) {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+	if err := s.applyOverrides(newCfg); err != nil {
+		return config.ChangeSet{}, err
 	}
-	time.Sleep(100 * time.Millisecond) // Краткая задержка
-	return s.manager.Start(name)
+	return s.apply(newCfg, dryRun)
 }
 
-func (s *Supervisor) ReloadConfig(newCfg *config.Config) {
-	s.StopAll()
-	s.config = newCfg
-	s.manager = process.NewManager(s.AddLog)
-	for _, pcfg := range newCfg.Processes {
-		s.manager.AddProcess(pcfg)
+// applyOverrides must be called with reloadMu held.
+func (s *Supervisor) applyOverrides(cfg *config.Config) error {
+	if s.overrides == nil {
+		return nil
 	}
-	s.StartAll()
+	return s.overrides(cfg)
+}
+
+// Reload re-reads the config file the supervisor was started with and
+// applies it like ReloadConfig. An invalid file leaves the running
+// configuration untouched.
+func (s *Supervisor) Reload(dryRun bool) (config.ChangeSet, error) {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	newCfg, err := config.Load(s.config.Path)
+	if err != nil {
+		return config.ChangeSet{}, err
+	}
+	if err := s.applyOverrides(newCfg); err != nil {
+		return config.ChangeSet{}, err
+	}
+	return s.apply(newCfg, dryRun)
+}
+
+// AddProcess adds a program at runtime and starts it if autostart is set.
+// Like UpdateProcess and RemoveProcess it changes the running
+// configuration only; the next reload from the config file undoes it.
+func (s *Supervisor) AddProcess(pc config.ProcessConfig, dryRun bool) (config.ChangeSet, error) {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	if s.config.HasProcess(pc.Name) {
+		return config.ChangeSet{}, fmt.Errorf("process %w: %s", process.ErrAlreadyExists, pc.Name)
+	}
+	newCfg, err := s.config.WithProcess(pc)
+	if err != nil {
+		return config.ChangeSet{}, err
+	}
+	return s.apply(newCfg, dryRun)
+}
+
+// UpdateProcess replaces the settings of a program, restarting it if they
+// changed and it was running.
+func (s *Supervisor) UpdateProcess(pc config.ProcessConfig, dryRun bool) (config.ChangeSet, error) {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	if !s.config.HasProcess(pc.Name) {
+		return config.ChangeSet{}, fmt.Errorf("process %w: %s", process.ErrNotFound, pc.Name)
+	}
+	newCfg, err := s.config.WithProcess(pc)
+	if err != nil {
+		return config.ChangeSet{}, err
+	}
+	return s.apply(newCfg, dryRun)
+}
+
+// RemoveProcess stops a program and drops it.
+func (s *Supervisor) RemoveProcess(name string, dryRun bool) (config.ChangeSet, error) {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	if !s.config.HasProcess(name) {
+		return config.ChangeSet{}, fmt.Errorf("process %w: %s", process.ErrNotFound, name)
+	}
+	newCfg, err := s.config.WithoutProcess(name)
+	if err != nil {
+		return config.ChangeSet{}, err
+	}
+	return s.apply(newCfg, dryRun)
+}
+
+// apply must be called with reloadMu held.
+func (s *Supervisor) apply(newCfg *config.Config, dryRun bool) (config.ChangeSet, error) {
+	changes := config.Diff(s.config, newCfg)
+	if dryRun {
+		return changes, nil
+	}
+
+	if newCfg.Control != s.config.Control {
+		s.Log(logging.LevelWarn, "Control socket settings take effect after a restart")
+	}
+	// Файлы сертификатов и токенов перечитываются по SIGHUP, сами секции - нет
+	if !reflect.DeepEqual(newCfg.GRPC, s.config.GRPC) || !reflect.DeepEqual(newCfg.Auth, s.config.Auth) {
+		s.Log(logging.LevelWarn, "gRPC listener and auth settings take effect after a restart")
+	}
+	if newCfg.Metrics != s.config.Metrics {
+		s.Log(logging.LevelWarn, "Metrics listener settings take effect after a restart")
+	}
+	if newCfg.Audit != s.config.Audit {
+		s.Log(logging.LevelWarn, "Audit log settings take effect after a restart")
+	}
+	s.config = newCfg
+	s.listeners.configure(newCfg)
+	s.notifier.Configure(newCfg.Notifications)
+	s.Log(logging.LevelInfo, "Applying configuration: %s", changes)
+	err := s.manager.Apply(newCfg, changes)
+
+	// Буферы удалённых процессов больше не нужны
+	statuses := s.manager.Status()
+	s.ring.Retain(func(id string) bool {
+		_, ok := statuses[id]
+		return ok
+	})
+	return changes, err
 }
 
 func (s *Supervisor) Status() map[string]*process.ProcessInfo {
@@ -107,8 +367,12 @@ func (s *Supervisor) GetProcessStatus(name string) process.Status {
 }
 
 func (s *Supervisor) PrintStatus() {
-	statuses := s.Status()
+	PrintStatuses(s.Status())
+}
 
+// PrintStatuses prints the status table, e.g. for statuses received from
+// a running daemon.
+func PrintStatuses(statuses map[string]*ProcessInfo) {
 	// Create colored printers
 	cyan := color.New(color.FgCyan).SprintFunc()
 	green := color.New(color.FgGreen).SprintFunc()
@@ -140,25 +404,29 @@ func (s *Supervisor) PrintStatus() {
 	currentTime := time.Now().Format("2006-01-02 15:04:05")
 	fmt.Println()
 	fmt.Println(magenta("PROCESS SUPERVISOR STATUS - " + currentTime))
-	fmt.Println(strings.Repeat("-", maxNameLen+maxPidLen+35))
+	fmt.Println(strings.Repeat("-", maxNameLen+maxPidLen+62))
 
 	// Header with Restarts column
 	fmt.Printf(
-		"%s | %s | %-8s | %-8s | %-7s\n",
+		"%s | %s | %-8s | %-8s | %-5s | %-7s | %-6s | %-7s\n",
 		cyan(fmt.Sprintf(nameFormat, "Process")),
 		cyan(fmt.Sprintf(pidFormat, "PID")),
 		cyan("Status"),
 		cyan("Uptime"),
+		cyan("Ready"),
 		cyan("Restarts"),
+		cyan("CPU"),
+		cyan("Memory"),
 	)
-	fmt.Println(strings.Repeat("-", maxNameLen+maxPidLen+35))
+	fmt.Println(strings.Repeat("-", maxNameLen+maxPidLen+62))
 
 	// Process data
 	running := 0
 	failed := 0
 	active := 0
 
-	for name, info := range statuses {
+	for _, name := range sortedNames(statuses) {
+		info := statuses[name]
 		pidStr := "N/A"
 		if info.PID > 0 {
 			pidStr = fmt.Sprintf("%d", info.PID)
@@ -176,62 +444,209 @@ func (s *Supervisor) PrintStatus() {
 			statusStr = green(fmt.Sprintf("%-8s", info.Status))
 			running++
 			active++
-		case process.Starting, process.Stopping:
+		case process.Starting, process.Stopping, process.Backoff:
 			statusStr = yellow(fmt.Sprintf("%-8s", info.Status))
 			active++
-		case process.Failed:
+		case process.Failed, process.Fatal:
 			statusStr = red(fmt.Sprintf("%-8s", info.Status))
 			failed++
-		case process.Stopped:
+		case process.Stopped, process.Exited:
 			statusStr = blue(fmt.Sprintf("%-8s", info.Status))
 		default:
 			statusStr = cyan(fmt.Sprintf("%-8s", info.Status))
 		}
 
 		// Highlight restarts when near limit
-		restarts := fmt.Sprintf("%d", info.Restarts)
-		if info.Restarts >= process.MaxRestarts-1 {
+		restarts := formatRestarts(info)
+		if nearRestartLimit(info) {
 			restarts = yellow(restarts)
 		} else if info.Restarts > 0 {
 			restarts = cyan(restarts)
 		}
 
+		ready := fmt.Sprintf("%-5s", formatReady(info))
+		if info.Ready {
+			ready = green(ready)
+		} else if info.Status == process.Running {
+			ready = yellow(ready)
+		}
+
 		fmt.Printf(
-			"%s | %s | %s | %s | %s\n",
+			"%s | %s | %s | %s | %s | %s | %-6s | %-7s\n",
 			fmt.Sprintf(nameFormat, name),
 			fmt.Sprintf(pidFormat, pidStr),
 			statusStr, // Используем цветную строку статуса
 			fmt.Sprintf("%-8s", uptime),
+			ready,
 			fmt.Sprintf("%-7s", restarts),
+			formatCPU(info),
+			formatMemory(info),
 		)
 
 		// Show error details for failed processes
-		if info.Status == process.Failed && info.ExitError != nil {
-			fmt.Printf("  └─ %s\n", red(info.ExitError.Error()))
+		switch info.Status {
+		case process.Failed, process.Fatal, process.Backoff:
+			if info.StartRetries > 0 {
+				fmt.Printf("  └─ %s\n", yellow(fmt.Sprintf("failed start attempts: %d", info.StartRetries)))
+			}
+			if info.ExitError != nil {
+				fmt.Printf("  └─ %s\n", red(info.ExitError.Error()))
+			}
 		}
 	}
 
-	fmt.Println(strings.Repeat("-", maxNameLen+maxPidLen+35))
-	fmt.Printf("Processes: %d | ", len(statuses))
-	green("Running: %d", running)
-	fmt.Print(" | ")
-	red("Failed: %d", failed)
-	fmt.Print(" | ")
-	yellow("Active: %d", active)
-	fmt.Print(" | ")
-	cyan("Max restarts: %d\n\n", process.MaxRestarts)
+	// Group summary
+	if groups := groupStatuses(statuses); len(groups) > 0 {
+		fmt.Println(strings.Repeat("-", maxNameLen+maxPidLen+62))
+		for _, g := range groups {
+			summary := fmt.Sprintf("%-12s", g.summary())
+			switch {
+			case g.Failed > 0:
+				summary = red(summary)
+			case g.Running == g.Total:
+				summary = green(summary)
+			default:
+				summary = yellow(summary)
+			}
+			fmt.Printf("%s | %s\n", fmt.Sprintf(nameFormat, g.Name+":*"), summary)
+		}
+	}
+
+	fmt.Println(strings.Repeat("-", maxNameLen+maxPidLen+62))
+	fmt.Printf("Processes: %d | %s | %s | %s\n\n",
+		len(statuses),
+		green(fmt.Sprintf("Running: %d", running)),
+		red(fmt.Sprintf("Failed: %d", failed)),
+		yellow(fmt.Sprintf("Active: %d", active)),
+	)
+}
+
+// formatRestarts shows the restart count against the process's budget.
+func formatRestarts(info *ProcessInfo) string {
+	if info.MaxRestarts < 0 {
+		return fmt.Sprintf("%d", info.Restarts)
+	}
+	if info.RestartWindow > 0 {
+		return fmt.Sprintf("%d/%d per %v", info.Restarts, info.MaxRestarts, info.RestartWindow)
+	}
+	return fmt.Sprintf("%d/%d", info.Restarts, info.MaxRestarts)
+}
+
+// formatReady shows readiness only for running processes.
+func formatReady(info *ProcessInfo) string {
+	switch {
+	case info.Ready:
+		return "yes"
+	case info.Status == process.Running:
+		return "no"
+	default:
+		return "-"
+	}
+}
+
+// formatCPU and formatMemory show the last usage sample of the process
+// tree, "-" if there is none.
+func formatCPU(info *ProcessInfo) string {
+	if !info.Usage.Sampled() {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", info.Usage.CPUPercent)
+}
+
+func formatMemory(info *ProcessInfo) string {
+	if !info.Usage.Sampled() {
+		return "-"
+	}
+	return formatBytes(info.Usage.RSS)
+}
+
+func formatBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	value, suffix := float64(n)/unit, "KMGTPE"
+	for value >= unit && len(suffix) > 1 {
+		value, suffix = value/unit, suffix[1:]
+	}
+	return fmt.Sprintf("%.1f%c", value, suffix[0])
+}
+
+func nearRestartLimit(info *ProcessInfo) bool {
+	return info.MaxRestarts >= 0 && info.Restarts >= info.MaxRestarts-1
+}
+
+// groupStatus aggregates the state of a group's members.
+type groupStatus struct {
+	Name    string
+	Total   int
+	Running int
+	Failed  int
+}
+
+func (g groupStatus) summary() string {
+	s := fmt.Sprintf("%d/%d running", g.Running, g.Total)
+	if g.Failed > 0 {
+		s += fmt.Sprintf(", %d failed", g.Failed)
+	}
+	return s
+}
+
+// groupStatuses returns the aggregated status of every group, sorted by
+// group name.
+func groupStatuses(statuses map[string]*ProcessInfo) []groupStatus {
+	byName := make(map[string]*groupStatus)
+	for _, info := range statuses {
+		if info.Group == "" {
+			continue
+		}
+		g, ok := byName[info.Group]
+		if !ok {
+			g = &groupStatus{Name: info.Group}
+			byName[info.Group] = g
+		}
+		g.Total++
+		switch info.Status {
+		case process.Running:
+			g.Running++
+		case process.Failed, process.Fatal:
+			g.Failed++
+		}
+	}
+
+	groups := make([]groupStatus, 0, len(byName))
+	for _, g := range byName {
+		groups = append(groups, *g)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Name < groups[j].Name })
+	return groups
+}
+
+// sortedNames orders processes by group and then by name, so members of a
+// group are listed together; processes without a group come last.
+func sortedNames(statuses map[string]*ProcessInfo) []string {
+	names := make([]string, 0, len(statuses))
+	for name := range statuses {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		gi, gj := statuses[names[i]].Group, statuses[names[j]].Group
+		if gi != gj {
+			return gj == "" || (gi != "" && gi < gj)
+		}
+		return names[i] < names[j]
+	})
+	return names
 }
 
 func (s *Supervisor) RunTUI() {
 	app := tview.NewApplication()
 
-	// Устанавливаем логгер для TUI
-	s.SetLogger(s.AddLog)
-
 	// Create process status table
 	table := tview.NewTable().
 		SetBorders(true).
-		SetFixed(1, 1)
+		SetFixed(1, 1).
+		SetSelectable(true, false)
 
 	// Configure headers
 	headerStyle := tcell.Style{}.
@@ -243,7 +658,12 @@ func (s *Supervisor) RunTUI() {
 	table.SetCell(0, 1, tview.NewTableCell("PID").SetStyle(headerStyle))
 	table.SetCell(0, 2, tview.NewTableCell("Status").SetStyle(headerStyle))
 	table.SetCell(0, 3, tview.NewTableCell("Uptime").SetStyle(headerStyle))
-	table.SetCell(0, 4, tview.NewTableCell("Restarts").SetStyle(headerStyle))
+	table.SetCell(0, 4, tview.NewTableCell("Ready").SetStyle(headerStyle))
+	table.SetCell(0, 5, tview.NewTableCell("Restarts").SetStyle(headerStyle))
+	table.SetCell(0, 6, tview.NewTableCell("CPU").SetStyle(headerStyle))
+	table.SetCell(0, 7, tview.NewTableCell("Memory").SetStyle(headerStyle))
+	table.SetCell(0, 8, tview.NewTableCell("Threads").SetStyle(headerStyle))
+	table.SetCell(0, 9, tview.NewTableCell("FDs").SetStyle(headerStyle))
 
 	// Текстовое поле для логов с буферизацией
 	logView := tview.NewTextView().
@@ -252,9 +672,22 @@ func (s *Supervisor) RunTUI() {
 			app.Draw()
 		})
 
-	logView.SetBorder(true).SetTitle("Logs")
+	logView.SetBorder(true)
 	logView.SetScrollable(true)
 
+	// Фильтр логов: 'f' - только выбранный процесс или группа,
+	// 'l' - следующий минимальный уровень
+	var logFilter logging.Filter
+	filterName := ""
+	updateLogTitle := func() {
+		title := fmt.Sprintf("Logs (level: %s+", logFilter.MinLevel)
+		if filterName != "" {
+			title += ", process: " + filterName
+		}
+		logView.SetTitle(title + ") - f: filter by selected, l: level")
+	}
+	updateLogTitle()
+
 	// Создаем flex-контейнер с правильными пропорциями
 	flex := tview.NewFlex().
 		SetDirection(tview.FlexRow).
@@ -265,7 +698,27 @@ func (s *Supervisor) RunTUI() {
 	updateTable := func() {
 		statuses := s.Status()
 		row := 1
-		for name, info := range statuses {
+
+		// Строки групп: выбор такой строки и 'r' перезапускают всю группу
+		for _, g := range groupStatuses(statuses) {
+			groupColor := tcell.ColorYellow
+			switch {
+			case g.Failed > 0:
+				groupColor = tcell.ColorRed
+			case g.Running == g.Total:
+				groupColor = tcell.ColorGreen
+			}
+			table.SetCell(row, 0, tview.NewTableCell(g.Name+":*").SetTextColor(tcell.ColorFuchsia))
+			table.SetCell(row, 1, tview.NewTableCell(""))
+			table.SetCell(row, 2, tview.NewTableCell(g.summary()).SetTextColor(groupColor))
+			for col := 3; col <= 9; col++ {
+				table.SetCell(row, col, tview.NewTableCell(""))
+			}
+			row++
+		}
+
+		for _, name := range sortedNames(statuses) {
+			info := statuses[name]
 			pidStr := "N/A"
 			if info.PID > 0 {
 				pidStr = fmt.Sprintf("%d", info.PID)
@@ -281,19 +734,26 @@ func (s *Supervisor) RunTUI() {
 			switch info.Status {
 			case process.Running:
 				color = tcell.ColorGreen
-			case process.Starting, process.Stopping:
+			case process.Starting, process.Stopping, process.Backoff:
 				color = tcell.ColorYellow
-			case process.Failed:
+			case process.Failed, process.Fatal:
 				color = tcell.ColorRed
-			case process.Stopped:
+			case process.Stopped, process.Exited:
 				color = tcell.ColorBlue
 			default:
 				color = tcell.ColorWhite
 			}
 
+			readyColor := tcell.ColorWhite
+			if info.Ready {
+				readyColor = tcell.ColorGreen
+			} else if info.Status == process.Running {
+				readyColor = tcell.ColorYellow
+			}
+
 			// Restarts cell color
 			restartColor := tcell.ColorWhite
-			if info.Restarts >= process.MaxRestarts-1 {
+			if nearRestartLimit(info) {
 				restartColor = tcell.ColorYellow
 			} else if info.Restarts > 0 {
 				restartColor = tcell.Color(6) // Cyan color
@@ -304,8 +764,18 @@ func (s *Supervisor) RunTUI() {
 			table.SetCell(row, 2, tview.NewTableCell(string(info.Status)).
 				SetTextColor(color))
 			table.SetCell(row, 3, tview.NewTableCell(uptime))
-			table.SetCell(row, 4, tview.NewTableCell(fmt.Sprintf("%d", info.Restarts)).
+			table.SetCell(row, 4, tview.NewTableCell(formatReady(info)).
+				SetTextColor(readyColor))
+			table.SetCell(row, 5, tview.NewTableCell(formatRestarts(info)).
 				SetTextColor(restartColor))
+			table.SetCell(row, 6, tview.NewTableCell(formatCPU(info)))
+			table.SetCell(row, 7, tview.NewTableCell(formatMemory(info)))
+			threads, fds := "-", "-"
+			if info.Usage.Sampled() {
+				threads, fds = fmt.Sprintf("%d", info.Usage.Threads), fmt.Sprintf("%d", info.Usage.FDs)
+			}
+			table.SetCell(row, 8, tview.NewTableCell(threads))
+			table.SetCell(row, 9, tview.NewTableCell(fds))
 			row++
 		}
 
@@ -317,12 +787,16 @@ func (s *Supervisor) RunTUI() {
 
 	// Функция обновления логов
 	updateLogs := func() {
-		s.logMu.Lock()
-		defer s.logMu.Unlock()
-
 		logView.Clear()
-		for _, log := range s.logs {
-			fmt.Fprintln(logView, log)
+		for _, rec := range s.Logs(logFilter, 0) {
+			line := tview.Escape(rec.Time.Format("15:04:05") + " " + rec.String())
+			switch rec.Level {
+			case logging.LevelError:
+				line = "[red]" + line + "[-]"
+			case logging.LevelWarn:
+				line = "[yellow]" + line + "[-]"
+			}
+			fmt.Fprintln(logView, line)
 		}
 
 		// Автопрокрутка к концу
@@ -333,13 +807,21 @@ func (s *Supervisor) RunTUI() {
 	updateTable()
 	updateLogs()
 
-	// Автообновление
+	// Автообновление: таблица сразу реагирует на смену статуса, а раз в
+	// секунду обновляются uptime и логи
+	events, cancelEvents := s.SubscribeEvents()
+	defer cancelEvents()
 	go func() {
 		ticker := time.NewTicker(1 * time.Second)
 		defer ticker.Stop()
 
 		for {
 			select {
+			case _, ok := <-events:
+				if !ok {
+					return
+				}
+				app.QueueUpdateDraw(updateTable)
 			case <-ticker.C:
 				app.QueueUpdateDraw(func() {
 					updateTable()
@@ -372,12 +854,40 @@ func (s *Supervisor) RunTUI() {
 					if cell != nil {
 						processName := cell.Text
 						go func() {
-							s.manager.Stop(processName)
-							time.Sleep(100 * time.Millisecond)
-							s.manager.Start(processName)
+							err := s.RestartProcess(processName)
+							if err != nil {
+								s.Log(logging.LevelError, "Failed to restart %s: %v", processName, err)
+							}
+							s.Audit(audit.NewRecord(audit.LocalActor(), "tui", "restart", processName, err))
 						}()
 					}
 				}
+				return nil
+			case 'f', 'F':
+				row, _ := table.GetSelection()
+				name := ""
+				if cell := table.GetCell(row, 0); row > 0 && cell != nil && cell.Text != filterName {
+					name = cell.Text
+				}
+				filterName = name
+				logFilter.Processes = nil
+				if group, ok := strings.CutSuffix(name, ":*"); ok {
+					for proc, info := range s.Status() {
+						if info.Group == group {
+							logFilter.Processes = append(logFilter.Processes, proc)
+						}
+					}
+				} else if name != "" {
+					logFilter.Processes = []string{name}
+				}
+				updateLogTitle()
+				updateLogs()
+				return nil
+			case 'l', 'L':
+				logFilter.MinLevel = (logFilter.MinLevel + 1) % (logging.LevelError + 1)
+				updateLogTitle()
+				updateLogs()
+				return nil
 			}
 		}
 		return event
@@ -405,7 +915,7 @@ func formatUptime(d time.Duration) string {
 
 func (s *Supervisor) RunDaemon(grpcPort string) {
 	if err := s.StartAll(); err != nil {
-		log.Fatalf("[ERROR] Startup failed: %v", err)
+		log.Printf("[ERROR] Startup incomplete: %v", err)
 	}
 
 	// Основной цикл демона